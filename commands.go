@@ -24,9 +24,10 @@ func init() {
 	commands = []command{
 		{[]string{"q", "quit"}, cmdQuit, "Exits RpnCalc"},
 		{[]string{"s", "stack"}, cmdStack, "Stack. Use \"stack clear\" to empty stack"},
-		{[]string{"r", "regs"}, cmdRegs, "Registers. User \"regs clear\" to empty registers"},
-		{[]string{"hi", "history"}, cmdHistory, "History. use \"history clear\" or \"history write <filepath>\" to save"},
-		{[]string{"set"}, cmdSetting, "Show or set configuration. use \"set <setting> <value>\" to change"},
+		{[]string{"r", "regs"}, cmdRegs, "Registers and variables. User \"regs clear\" to empty registers and remove variables, \"regs stats\" to show statistics, \"regs tvm\" or \"regs amort <from> <to>\" for time value of money"},
+		{[]string{"w", "words"}, cmdWords, "User defined words. Use \"words clear\", \"words write <filepath>\" or \"words read <filepath>\""},
+		{[]string{"hi", "history"}, cmdHistory, "History. use \"history clear\" or \"history write <filepath>\" to save as a script, including changes of the engine settings"},
+		{[]string{"set"}, cmdSetting, "Show or set configuration. use \"set <setting> <value>\" to change, \"set bits 0\" for float64 mode"},
 		{[]string{"?", "h", "help"}, cmdHelp, "Show RpnCalc help"},
 	}
 }
@@ -68,9 +69,17 @@ func cmdStack(r *rpncalc.RpnCalc, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("Stack:\n")
+	if r.Settings().StackSize == 0 {
+		fmt.Printf("Stack (dynamic, %d values):\n", r.Depth())
+		if r.Depth() == 0 {
+			fmt.Printf("  stack is empty\n")
+			return nil
+		}
+	} else {
+		fmt.Printf("Stack:\n")
+	}
 	for i := len(r.Stack()) - 1; i >= 0; i-- {
-		fmt.Printf("%3d: %10v", i, formatVal(r.Stack()[i]))
+		fmt.Printf("%3d: %10v", i, formatVal(r, r.Stack()[i]))
 		if i != 0 {
 			fmt.Printf("\n")
 		}
@@ -82,16 +91,65 @@ func cmdStack(r *rpncalc.RpnCalc, args []string) error {
 
 func cmdRegs(r *rpncalc.RpnCalc, args []string) error {
 	if len(args) > 1 {
-		if args[1] != "clear" {
+		switch args[1] {
+		case "clear":
+			r.ClearRegs()
+			r.ClearVars()
+		case "stats":
+			s := r.Stats()
+			fmt.Printf("Statistics:\n")
+			fmt.Printf("  %5v: %v\n", "n", s.N)
+			fmt.Printf("  %5v: %v\n", "Σx", s.SumX)
+			fmt.Printf("  %5v: %v\n", "Σx²", s.SumX2)
+			fmt.Printf("  %5v: %v\n", "Σy", s.SumY)
+			fmt.Printf("  %5v: %v\n", "Σy²", s.SumY2)
+			fmt.Printf("  %5v: %v\n", "Σxy", s.SumXY)
+		case "tvm":
+			t := r.TVM()
+			fmt.Printf("Time value of money:\n")
+			fmt.Printf("  %5v: %v\n", "n", t.N)
+			fmt.Printf("  %5v: %v\n", "i", t.I)
+			fmt.Printf("  %5v: %v\n", "pv", t.PV)
+			fmt.Printf("  %5v: %v\n", "pmt", t.PMT)
+			fmt.Printf("  %5v: %v\n", "fv", t.FV)
+			fmt.Printf("  %5v: %v\n", "begin", t.Begin)
+		case "amort":
+			if len(args) < 4 {
+				return fmt.Errorf("use \"regs amort <from> <to>\"")
+			}
+			from, err1 := strconv.Atoi(args[2])
+			to, err2 := strconv.Atoi(args[3])
+			if err1 != nil || err2 != nil {
+				return fmt.Errorf("periods are not numbers")
+			}
+			rows, err := r.Amortize(from, to)
+			if err != nil {
+				return fmt.Errorf("periods %v to %v are not in the loan of %v periods", from, to, r.TVM().N)
+			}
+			fmt.Printf("Amortization:\n")
+			fmt.Printf("  %6v %14v %14v %14v\n", "period", "interest", "principal", "balance")
+			p := config.DisplayPrecision
+			for _, a := range rows {
+				fmt.Printf("  %6v %14.*f %14.*f %14.*f\n", a.Period, p, a.Interest, p, a.Principal, p, a.Balance)
+			}
+		default:
 			return fmt.Errorf("%q no such option", args[1])
 		}
-		r.ClearRegs()
 		return nil
 	}
 
 	fmt.Printf("Registers:\n")
 	for i, v := range r.Regs() {
-		fmt.Printf("  %2d: %v\n", i, formatVal(v))
+		fmt.Printf("  %2d: %v\n", i, formatVal(r, v))
+	}
+	fmt.Printf("  %v: %v\n", "lastx", formatVal(r, r.LastX()))
+	fmt.Printf("  %v: %v\n", "lasty", formatVal(r, r.LastY()))
+
+	if len(r.Vars()) > 0 {
+		fmt.Printf("Variables:\n")
+		for _, v := range r.Vars() {
+			fmt.Printf("  %v: %v\n", v.Name, formatVal(r, v.Value))
+		}
 	}
 
 	return nil
@@ -100,7 +158,7 @@ func cmdRegs(r *rpncalc.RpnCalc, args []string) error {
 func cmdSetting(r *rpncalc.RpnCalc, args []string) error {
 	if len(args) < 2 {
 		// show all configuration
-		fmt.Printf("%v\n", jsonConfig())
+		fmt.Printf("%v\n", jsonConfig(r))
 		return nil
 	}
 
@@ -129,6 +187,166 @@ func cmdSetting(r *rpncalc.RpnCalc, args []string) error {
 				config.ShowStack = t
 			}
 			fmt.Printf(f, "showstack", config.ShowStack)
+		case "bits":
+			if len(args) > 2 {
+				b, err := strconv.ParseUint(args[2], 10, 32)
+				if err != nil {
+					return fmt.Errorf("bits value is not a number")
+				}
+				if b > rpncalc.MaxPrecision {
+					return fmt.Errorf("bits can not be larger than %v", rpncalc.MaxPrecision)
+				}
+				token := "f64"
+				if b > 0 {
+					token = fmt.Sprintf("big%d", b)
+				}
+				if err := r.Evaluate(token); err != nil {
+					return fmt.Errorf("could not switch to %v bits: %v", b, err)
+				}
+			}
+			fmt.Printf(f, "bits", r.Settings().Precision)
+		case "undodepth":
+			if len(args) > 2 {
+				d, err := strconv.Atoi(args[2])
+				if err != nil {
+					return fmt.Errorf("undo depth value is not a number")
+				}
+				if d < 0 {
+					return fmt.Errorf("negative undo depth is not allowed")
+				}
+				r.SetUndoDepth(d)
+			}
+			fmt.Printf(f, "undodepth", r.Settings().UndoDepth)
+		case "undotokens":
+			if len(args) > 2 {
+				t, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				r.SetUndoTokens(t)
+			}
+			fmt.Printf(f, "undotokens", r.Settings().UndoTokens)
+		case "steplimit":
+			if len(args) > 2 {
+				l, err := strconv.Atoi(args[2])
+				if err != nil {
+					return fmt.Errorf("step limit value is not a number")
+				}
+				if l < 0 {
+					return fmt.Errorf("negative step limit is not allowed")
+				}
+				r.SetStepLimit(l)
+			}
+			fmt.Printf(f, "steplimit", r.Settings().StepLimit)
+		case "strict":
+			if len(args) > 2 {
+				t, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				token := "relaxed"
+				if t {
+					token = "strict"
+				}
+				_ = r.Evaluate(token)
+			}
+			fmt.Printf(f, "strict", r.Settings().Strict)
+		case "stacksize":
+			if len(args) > 2 {
+				n, err := strconv.Atoi(args[2])
+				if err != nil {
+					return fmt.Errorf("stack size value is not a number")
+				}
+				if err := r.Evaluate(fmt.Sprintf("stack%d", n)); err != nil {
+					return fmt.Errorf("stack size %v is not allowed, use 0 for a dynamic stack", n)
+				}
+			}
+			fmt.Printf(f, "stacksize", r.Settings().StackSize)
+		case "angle":
+			if len(args) > 2 {
+				m, err := rpncalc.ParseAngleMode(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not an angle mode, use rad, deg or grad", args[2])
+				}
+				_ = r.Evaluate(m.String())
+			}
+			fmt.Printf(f, "angle", r.Settings().Angle)
+		case "base":
+			if len(args) > 2 {
+				b, err := rpncalc.ParseBase(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a base, use dec, hex, oct or bin", args[2])
+				}
+				_ = r.Evaluate(b.String())
+			}
+			fmt.Printf(f, "base", r.Settings().Base)
+		case "wordsize":
+			if len(args) > 2 {
+				s, err := strconv.Atoi(args[2])
+				if err != nil || r.Evaluate(fmt.Sprintf("ws%d", s)) != nil {
+					return fmt.Errorf("%q is not a word size, use 8, 16, 32 or 64", args[2])
+				}
+			}
+			fmt.Printf(f, "wordsize", r.Settings().WordSize)
+		case "signed":
+			if len(args) > 2 {
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				token := "unsigned"
+				if b {
+					token = "signed"
+				}
+				_ = r.Evaluate(token)
+			}
+			fmt.Printf(f, "signed", r.Settings().Signed)
+		case "complex":
+			if len(args) > 2 {
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				token := "realmode"
+				if b {
+					token = "complexmode"
+				}
+				_ = r.Evaluate(token)
+			}
+			fmt.Printf(f, "complex", r.Settings().Complex)
+		case "polar":
+			if len(args) > 2 {
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				token := "rectmode"
+				if b {
+					token = "polarmode"
+				}
+				_ = r.Evaluate(token)
+			}
+			fmt.Printf(f, "polar", r.Settings().Polar)
+		case "fraction":
+			if len(args) > 2 {
+				b, err := strconv.ParseBool(args[2])
+				if err != nil {
+					return fmt.Errorf("%q is not a boolean value", args[2])
+				}
+				token := "decmode"
+				if b {
+					token = "fracmode"
+				}
+				_ = r.Evaluate(token)
+			}
+			fmt.Printf(f, "fraction", r.Settings().Fraction)
+		case "zone":
+			if len(args) > 2 {
+				if err := r.Evaluate("zone " + args[2]); err != nil {
+					return fmt.Errorf("unknown time zone: %q", args[2])
+				}
+			}
+			fmt.Printf(f, "zone", r.Settings().Zone)
 		default:
 			return fmt.Errorf("unknown setting: %q", args[1])
 		}
@@ -152,7 +370,7 @@ func cmdHistory(r *rpncalc.RpnCalc, args []string) error {
 		if len(args) < 3 {
 			return fmt.Errorf("write needs a file path as argument")
 		}
-		err := ioutil.WriteFile(args[2], []byte(strings.Join(r.Log(), "\n")+"\n"), 0644)
+		err := ioutil.WriteFile(args[2], []byte(rpncalc.Script(r.Log())), 0644)
 		if err != nil {
 			return fmt.Errorf("could not write log to %q", args[2])
 		}
@@ -173,6 +391,59 @@ func cmdHistory(r *rpncalc.RpnCalc, args []string) error {
 	return nil
 }
 
+func cmdWords(r *rpncalc.RpnCalc, args []string) error {
+	if len(args) > 1 {
+		switch args[1] {
+		case "clear":
+			r.ClearWords()
+			fmt.Println("  words cleared")
+			return nil
+		case "write":
+			if len(args) < 3 {
+				return fmt.Errorf("write needs a file path as argument")
+			}
+			defs := ""
+			for _, w := range r.Words() {
+				defs += w.Definition() + "\n"
+			}
+			err := ioutil.WriteFile(args[2], []byte(defs), 0644)
+			if err != nil {
+				return fmt.Errorf("could not write words to %q", args[2])
+			}
+			return nil
+		case "read":
+			if len(args) < 3 {
+				return fmt.Errorf("read needs a file path as argument")
+			}
+			bs, err := ioutil.ReadFile(args[2])
+			if err != nil {
+				return fmt.Errorf("could not read words from %q", args[2])
+			}
+			for i, l := range strings.Split(string(bs), "\n") {
+				err := r.Evaluate(l)
+				if err != nil {
+					return fmt.Errorf("%v on line %d in %q", err, i+1, args[2])
+				}
+			}
+			return nil
+		default:
+			return fmt.Errorf("%q no such option", args[1])
+		}
+	}
+
+	// if no words
+	if len(r.Words()) < 1 {
+		fmt.Println("  no words defined")
+		return nil
+	}
+
+	fmt.Printf("Words:\n")
+	for _, w := range r.Words() {
+		fmt.Printf("  %v\n", w.Definition())
+	}
+	return nil
+}
+
 func cmdHelp(r *rpncalc.RpnCalc, _ []string) error {
 	format := "  %20v: %v\n"
 	cmds := fmt.Sprintf(format, "Command", "Description")
@@ -181,7 +452,7 @@ func cmdHelp(r *rpncalc.RpnCalc, _ []string) error {
 	}
 
 	ops := ""
-	for _, op := range rpncalc.OpsInfo() {
+	for _, op := range r.OpsInfo() {
 		cmds := op.Prefix
 		if cmds == "" {
 			cmds = strings.Join(op.Names, ", ")
@@ -202,6 +473,11 @@ func cmdHelp(r *rpncalc.RpnCalc, _ []string) error {
 		consts += fmt.Sprintf(format, strings.Join(c.Names, ", "), fmt.Sprintf("%s, %s", val, c.Description))
 	}
 
+	units := ""
+	for _, u := range rpncalc.Units() {
+		units += fmt.Sprintf(format, strings.Join(u.Names, ", "), u.Description)
+	}
+
 	fmt.Printf(`
 RPN Calc Help
 
@@ -227,6 +503,63 @@ Input can be piped into rpn. Like:
 
 Unary operators will act on the first element in the stack, binary on the first two elements.
 
+New operators, words, can be defined as a sequence of tokens between ":" and ";". Example:
+    : hyp sq sw sq + sqrt ;
+    3 4 hyp
+
+Words can use operators, constants and other words. Remove a word with "forget <name>".
+
+Control structures can be used in input and in words. Zero is false and any other value is true.
+    cond if ... then
+    cond if ... else ... then
+    n times ... end
+    while cond ... do ... end
+
+The "if" and "times" take their argument from the stack and "while" repeats as long as the
+tokens before "do" leave a true value on the stack. Runaway loops are stopped by the
+setting "steplimit", the maximum number of tokens evaluated for one input (0 for no limit).
+
+Integers can be entered as hex, octal or binary literals, like 0xff, 0o17 or 0b101. The
+literals are bit patterns of the integer word size, see settings "wordsize" and "signed".
+The operators "dec", "hex", "oct" and "bin" select the base values are displayed in.
+
+Complex values can be entered like 3+4i or 2i, or created with "rect" and "polar". With the
+setting "complex" on, operations like sqrt of a negative value give complex results instead
+of errors. The setting "polar" displays complex values as magnitude and angle.
+
+Fractions like 1/3 are exact, "1/3 2/5 +" gives 11/15. In fraction mode, "fracmode" or the
+setting "fraction", decimals are entered as fractions and fractions are displayed as mixed
+numbers like 1 2/3. Use "frac" and "approx" to convert between fractions and decimals.
+
+Values can have units, like "12 m" or "3 ft". Values are converted when added, subtracted or
+compared, and units of different dimensions can not be mixed. Convert with "to", like
+"12 ft to m" or "100 C to F".
+
+Dates are entered like 2024-03-01 or 2024-03-01T14:30, times of day like 14:30 and durations
+like 1h30m or 2d. A date plus or minus a duration gives a date and the difference of two dates
+is a duration. Show a date in another time zone with "tz", like "now tz Asia/Tokyo". The
+setting "zone", or "zone <name>" in the input, is the time zone of entered dates.
+
+Registers are stored with "rsX", restored with "rrX", cleared with "rcX" and exchanged with x
+with "rxX". Register arithmetic like "rs+1" adds x to register 1 and "rr*1" multiplies x by
+register 1, also with -, / and *. The register can be addressed indirectly, "rr@" takes the
+register number from x and "rr@2" takes it from register 2.
+
+Variables are stored with "=name", like "0.05 =rate", and recalled with "$rate" or just "rate".
+Remove a variable with "forget <name>".
+
+Statistics are accumulated with "s+" and removed with "s-", x and y as a pair. The results
+are read with "sn", "ssum", "smean", "sdev" and "psdev", and the regression with "slope",
+"intercept", "corr", "yhat" and "xhat". The names sn, ssum and smean start with s since
+"sum" and "mean" act on the values on the stack and single letters, like n, are left for
+variables.
+
+Time value of money problems are solved like on a financial calculator. Store four of the
+registers n, i, pv, pmt and fv with "rsn", "rsi" and so on, then solve the fifth with "nper",
+"irate", "pv", "pmt" or "fv". The interest rate i is in percent per period, money paid is
+negative. Cash flows for "npv" and "irr" are entered on the stack, or stored in registers 0
+and up for "rnpv" and "rirr" when they do not fit the stack.
+
 List of operators:
 
 %v
@@ -236,7 +569,13 @@ CONSTANTS
 List of constants:
 
 %v
-`, cmds, ops, consts)
+
+UNITS
+
+List of units:
+
+%v
+`, cmds, ops, consts, units)
 
 	return nil
 }
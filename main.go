@@ -94,8 +94,11 @@ func main() {
 
 func calculate(r *rpncalc.RpnCalc, input string, outputResult bool) (err error) {
 
-	// Split multi statements
-	lines := strings.Split(input, config.StatementSeparator)
+	// Split multi statements, comments are kept as is
+	lines := []string{input}
+	if !strings.HasPrefix(strings.TrimSpace(input), "#") {
+		lines = splitStatements(input, config.StatementSeparator)
+	}
 
 	// Handle each statement
 	for _, line := range lines {
@@ -109,10 +112,12 @@ func calculate(r *rpncalc.RpnCalc, input string, outputResult bool) (err error)
 			return err
 		case isCommand(args[0]):
 			err = doCommand(r, args)
+		case args[0] == ":" && len(args) > 1 && isCommand(args[1]):
+			err = fmt.Errorf("%q is a command and can not be a word", args[1])
 		default:
 			err = r.Evaluate(line)
 			if err == nil && outputResult {
-				fmt.Printf("%s\n", formatVal(r.Val()))
+				fmt.Printf("%s\n", formatVal(r, r.Val()))
 				if config.ShowStack {
 					cmdStack(r, []string{"s"}) // reuse stack command
 				}
@@ -127,7 +132,7 @@ func prompt(r *rpncalc.RpnCalc, msg string) (p string) {
 	if config.ShowStack {
 		cmdStack(r, []string{"s"}) // reuse stack command
 	}
-	p = fmt.Sprintf("%v", formatVal(r.Val()))
+	p = fmt.Sprintf("(%d) %v %v", r.Depth(), r.Settings().Angle, formatVal(r, r.Val()))
 
 	if msg == "" {
 		p += " > "
@@ -138,13 +143,17 @@ func prompt(r *rpncalc.RpnCalc, msg string) (p string) {
 	return p
 }
 
-func formatVal(v float64) string {
-	f := fmt.Sprintf("%%.%vf", config.DisplayPrecision)
-	return fmt.Sprintf(f, v)
+func formatVal(r *rpncalc.RpnCalc, v rpncalc.Value) string {
+	return r.Format(v, config.DisplayPrecision)
 }
 
-func jsonConfig() string {
-	bs, err := json.MarshalIndent(config, "", "  ")
+func jsonConfig(r *rpncalc.RpnCalc) string {
+	all := struct {
+		Display interface{}      `json:"display"`
+		Engine  rpncalc.Settings `json:"engine"`
+	}{config, r.Settings()}
+
+	bs, err := json.MarshalIndent(all, "", "  ")
 	if err != nil {
 		return "could not display config"
 	}
@@ -0,0 +1,216 @@
+// Package rpncalc whole stack aggregate operations
+package rpncalc
+
+import "sort"
+
+// aggregateCount returns the number of values an aggregate operator acts
+// on, all entered values for the static form and X for the dynamic form,
+// like sum5
+func (r *RpnCalc) aggregateCount(t, prefix string) (int, error) {
+	if t == prefix {
+		if r.depth < 1 {
+			return 0, errStackUnderflow
+		}
+		return r.depth, nil
+	}
+
+	n, err := parseIndex(t[len(prefix):])
+	if err != nil || n < 1 || r.beyondStack(n-1) {
+		return 0, errIndexOutOfRange
+	}
+	if err := r.need(n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// aggregateArgs returns the values an aggregate operator acts on, the
+// deepest value first
+func (r *RpnCalc) aggregateArgs(t, prefix string) ([]Value, error) {
+	n, err := r.aggregateCount(t, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	vs := make([]Value, n)
+	for i := 0; i < n; i++ {
+		vs[i] = r.stack[n-1-i]
+	}
+	return vs, nil
+}
+
+// scratch runs f on a temporary dynamic stack holding the values vs, the
+// deepest value first, and returns the value left in pos 0. Operators run
+// on the scratch stack handle units, fractions and modes like on the stack.
+func (r *RpnCalc) scratch(vs []Value, f func() error) (Value, error) {
+	stack, depth, dynamic := r.stack, r.depth, r.dynamic
+	defer func() {
+		r.stack, r.depth, r.dynamic = stack, depth, dynamic
+	}()
+
+	r.stack = make([]Value, len(vs))
+	for i, v := range vs {
+		r.stack[len(vs)-1-i] = v
+	}
+	r.depth, r.dynamic = len(vs), true
+
+	if err := f(); err != nil {
+		return Value{}, err
+	}
+	return r.Val(), nil
+}
+
+// reduce combines the values vs with the binary operator f, from the
+// deepest value
+func (r *RpnCalc) reduce(vs []Value, f func(*RpnCalc, string) error) (Value, error) {
+	return r.scratch(vs, func() error {
+		for i := 1; i < len(vs); i++ {
+			if err := f(r, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mean returns the mean of the values vs
+func (r *RpnCalc) mean(vs []Value) (Value, error) {
+	sum, err := r.reduce(vs, opAddition)
+	if err != nil {
+		return Value{}, err
+	}
+	n, err := r.value(float64(len(vs)))
+	if err != nil {
+		return Value{}, err
+	}
+	return r.reduce([]Value{sum, n}, opDivision)
+}
+
+// sortValues sorts the values vs in increasing order
+func (r *RpnCalc) sortValues(vs []Value) ([]Value, error) {
+	sorted := append([]Value{}, vs...)
+
+	var err error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if err != nil {
+			return false
+		}
+		var less Value
+		less, err = r.reduce([]Value{sorted[i], sorted[j]}, opLess)
+		return err == nil && !less.IsZero()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sorted, nil
+}
+
+// replace replaces the first n values on the stack with v
+func (r *RpnCalc) replace(n int, v Value) {
+	for i := 1; i < n; i++ {
+		r.pop()
+	}
+	r.setX(v)
+}
+
+// aggregateOp replaces the values an aggregate operator acts on with the
+// result of f
+func (r *RpnCalc) aggregateOp(t, prefix string, f func([]Value) (Value, error)) error {
+	vs, err := r.aggregateArgs(t, prefix)
+	if err != nil {
+		return err
+	}
+
+	v, err := f(vs)
+	if err != nil {
+		return err
+	}
+
+	r.replace(len(vs), v)
+	return nil
+}
+
+// rearrangeOp replaces the values an aggregate operator acts on with the
+// values returned by f, the deepest value first
+func (r *RpnCalc) rearrangeOp(t, prefix string, f func([]Value) ([]Value, error)) error {
+	vs, err := r.aggregateArgs(t, prefix)
+	if err != nil {
+		return err
+	}
+
+	vs, err = f(vs)
+	if err != nil {
+		return err
+	}
+
+	for i, v := range vs {
+		r.stack[len(vs)-1-i] = v
+	}
+	return nil
+}
+
+func opSum(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "sum", func(vs []Value) (Value, error) {
+		return r.reduce(vs, opAddition)
+	})
+}
+
+func opProduct(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "prod", func(vs []Value) (Value, error) {
+		return r.reduce(vs, opMultiplication)
+	})
+}
+
+func opMean(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "mean", r.mean)
+}
+
+func opMedian(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "median", func(vs []Value) (Value, error) {
+		sorted, err := r.sortValues(vs)
+		if err != nil {
+			return Value{}, err
+		}
+
+		n := len(sorted)
+		if n%2 == 1 {
+			return sorted[n/2], nil
+		}
+		return r.mean(sorted[n/2-1 : n/2+1])
+	})
+}
+
+func opMin(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "min", func(vs []Value) (Value, error) {
+		sorted, err := r.sortValues(vs)
+		if err != nil {
+			return Value{}, err
+		}
+		return sorted[0], nil
+	})
+}
+
+func opMax(r *RpnCalc, t string) error {
+	return r.aggregateOp(t, "max", func(vs []Value) (Value, error) {
+		sorted, err := r.sortValues(vs)
+		if err != nil {
+			return Value{}, err
+		}
+		return sorted[len(sorted)-1], nil
+	})
+}
+
+// opSort sorts the values with the largest value in pos 0
+func opSort(r *RpnCalc, t string) error {
+	return r.rearrangeOp(t, "sort", r.sortValues)
+}
+
+func opReverse(r *RpnCalc, t string) error {
+	return r.rearrangeOp(t, "reverse", func(vs []Value) ([]Value, error) {
+		rev := make([]Value, len(vs))
+		for i, v := range vs {
+			rev[len(vs)-1-i] = v
+		}
+		return rev, nil
+	})
+}
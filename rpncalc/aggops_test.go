@@ -0,0 +1,75 @@
+package rpncalc
+
+import "testing"
+
+func TestAggregates(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		input   string
+		dynamic bool // use a dynamic stack instead of the fixed stack
+		exp     []string
+		err     error
+	}{
+		{"sum", "1 2 3 sum", false, []string{"6", "0", "0", "0"}, nil},
+		{"sum dynamic", "1 2 3 4 5 sum", true, []string{"15"}, nil},
+		{"sum first values", "1 2 3 4 sum3", false, []string{"9", "1", "1", "1"}, nil},
+		{"sum single value", "1 2 sum1", false, []string{"2", "1", "0", "0"}, nil},
+		{"sum zero values", "1 2 sum0", false, nil, errIndexOutOfRange},
+		{"sum empty stack", "sum", false, nil, errStackUnderflow},
+		{"sum too many", "1 2 sum3", true, []string{"3"}, nil},
+		{"sum too deep", "1 2 sum100000000", true, nil, errIndexOutOfRange},
+		{"sum too many fixed", "1 2 sum3", false, []string{"3", "0", "0", "0"}, nil},
+		{"product", "2 3 4 prod", false, []string{"24", "0", "0", "0"}, nil},
+		{"mean", "1 2 3 4 mean", false, []string{"2.5", "1", "1", "1"}, nil},
+		{"median odd", "5 1 3 median", false, []string{"3", "0", "0", "0"}, nil},
+		{"median even", "4 1 3 2 median", false, []string{"2.5", "4", "4", "4"}, nil},
+		{"min", "3 7 2 min", false, []string{"2", "0", "0", "0"}, nil},
+		{"max", "3 7 2 max", false, []string{"7", "0", "0", "0"}, nil},
+		{"max first values", "9 3 7 2 max2", false, []string{"7", "3", "9", "9"}, nil},
+		{"sort", "3 1 2 sort", false, []string{"3", "2", "1", "0"}, nil},
+		{"sort first values", "3 1 5 2 sort2", false, []string{"5", "2", "1", "3"}, nil},
+		{"reverse", "1 2 3 reverse", true, []string{"1", "2", "3"}, nil},
+		{"reverse first values", "1 2 3 4 reverse2", false, []string{"3", "4", "2", "1"}, nil},
+		{"sum units", "1 m 50 cm 2 m sum", true, []string{"3.5 m"}, nil},
+		{"sum mixed units", "1 m 1 s sum", true, nil, errDimensionMismatch},
+		{"max units", "1 ft 1 m 1 in max", true, []string{"1 m"}, nil},
+		{"sum fractions", "1/2 1/3 1/6 sum", true, []string{"1"}, nil},
+		{"mean fractions", "1/2 1/3 mean", true, []string{"5/12"}, nil},
+		{"sort complex", "1 2i sort", true, nil, errComplex},
+		{"unit prefix", "5 minute", true, []string{"5 minute"}, nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+		if c.dynamic {
+			if err := r.SetStackSize(0); err != nil {
+				t.Fatalf("Unexpected error %v", err)
+			}
+		}
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		got := []string{}
+		for _, v := range r.Stack() {
+			got = append(got, v.String())
+		}
+		if len(got) != len(c.exp) {
+			t.Errorf("%q: Expected stack %v, but got %v", c.name, c.exp, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.exp[i] {
+				t.Errorf("%q: Expected stack %v, but got %v", c.name, c.exp, got)
+				break
+			}
+		}
+	}
+}
@@ -1,20 +1,105 @@
 // Package rpncalc operators
 package rpncalc
 
-import "math"
+import (
+	"math"
+	"math/big"
+	"math/cmplx"
+)
 
 func (r *RpnCalc) binaryOp(f func(float64, float64) (float64, error)) error {
-	v, err := f(r.stack[1], r.stack[0])
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+	if err := r.noUnits(2); err != nil {
+		return err
+	}
+	if err := r.noTimes(2); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[1].Float64(), r.stack[0].Float64())
+	if err != nil {
+		return err
+	}
+
+	v, err := r.value(z)
 	if err != nil {
 		return err
 	}
 
-	r.stack = rolldown(r.stack)
-	r.stack[0] = v
+	r.pop()
+	r.setX(v)
 	return nil
 }
 
-func opAddition(r *RpnCalc, _ string) error {
+func (r *RpnCalc) bigBinaryOp(f func(*big.Float, *big.Float) (*big.Float, error)) (err error) {
+	defer bigNaN(&err)
+
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+	if err := r.noUnits(2); err != nil {
+		return err
+	}
+	if err := r.noTimes(2); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[1].Big(r.prec), r.stack[0].Big(r.prec))
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(NewBig(z))
+	return nil
+}
+
+// bigNaN recovers from the panic of a big.Float operation without a
+// result, like infinity minus infinity, and sets err to errDomain
+func bigNaN(err *error) {
+	if e := recover(); e != nil {
+		if _, ok := e.(big.ErrNaN); !ok {
+			panic(e)
+		}
+		*err = errDomain
+	}
+}
+
+func opAddition(r *RpnCalc, t string) error {
+	if r.timeArgs(2) {
+		return opTimeAdd(r, t)
+	}
+
+	if r.unitArgs(2) {
+		return r.sameUnitOp(opAddition, "", true)
+	}
+
+	if r.complexArgs(2, nil) {
+		return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+			return y + x, nil
+		})
+	}
+
+	if r.ratArgs(2) {
+		return r.ratBinaryOp(func(y, x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Add(y, x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			return r.newBig().Add(x, y), nil
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		z := x + y
 		if math.IsInf(z, 1) || math.IsInf(z, -1) {
@@ -24,7 +109,33 @@ func opAddition(r *RpnCalc, _ string) error {
 	})
 }
 
-func opSubtraction(r *RpnCalc, _ string) error {
+func opSubtraction(r *RpnCalc, t string) error {
+	if r.timeArgs(2) {
+		return opTimeSubtract(r, t)
+	}
+
+	if r.unitArgs(2) {
+		return r.sameUnitOp(opSubtraction, "", true)
+	}
+
+	if r.complexArgs(2, nil) {
+		return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+			return y - x, nil
+		})
+	}
+
+	if r.ratArgs(2) {
+		return r.ratBinaryOp(func(y, x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Sub(y, x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			return r.newBig().Sub(x, y), nil
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		z := x - y
 		if math.IsInf(z, 1) || math.IsInf(z, -1) {
@@ -35,6 +146,28 @@ func opSubtraction(r *RpnCalc, _ string) error {
 }
 
 func opMultiplication(r *RpnCalc, _ string) error {
+	if r.unitArgs(2) {
+		return r.composeUnitOp(opMultiplication, "", 1)
+	}
+
+	if r.complexArgs(2, nil) {
+		return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+			return y * x, nil
+		})
+	}
+
+	if r.ratArgs(2) {
+		return r.ratBinaryOp(func(y, x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Mul(y, x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			return r.newBig().Mul(x, y), nil
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		z := x * y
 		if math.IsInf(z, 1) || math.IsInf(z, -1) {
@@ -45,6 +178,37 @@ func opMultiplication(r *RpnCalc, _ string) error {
 }
 
 func opDivision(r *RpnCalc, _ string) error {
+	if r.unitArgs(2) {
+		return r.composeUnitOp(opDivision, "", -1)
+	}
+
+	if r.complexArgs(2, nil) {
+		return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+			if x == 0 {
+				return 0, errDivisionByZero
+			}
+			return y / x, nil
+		})
+	}
+
+	if r.ratArgs(2) {
+		return r.ratBinaryOp(func(y, x *big.Rat) (*big.Rat, error) {
+			if x.Sign() == 0 {
+				return nil, errDivisionByZero
+			}
+			return new(big.Rat).Quo(y, x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			if y.Sign() == 0 {
+				return nil, errDivisionByZero
+			}
+			return r.newBig().Quo(x, y), nil
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		if y == 0.0 {
 			return 0.0, errDivisionByZero
@@ -55,12 +219,48 @@ func opDivision(r *RpnCalc, _ string) error {
 }
 
 func opPower(r *RpnCalc, _ string) error {
+	if r.complexPowArgs() {
+		return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+			return cmplx.Pow(y, x), nil
+		})
+	}
+
+	if r.ratArgs(2) && r.Val().Rat().IsInt() {
+		return r.ratBinaryOp(ratPow)
+	}
+
+	if r.precise() && r.Val().Big(r.prec).IsInt() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			return bigPow(x, y, r.prec)
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		return math.Pow(x, y), nil
 	})
 }
 
 func opModulus(r *RpnCalc, _ string) error {
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			if y.Sign() <= 0 {
+				return nil, errValueNotAllowed
+			}
+			if x.IsInf() || y.IsInf() {
+				return nil, errOverflow
+			}
+
+			xi, _ := x.Int(nil)
+			yi, _ := y.Int(nil)
+			if yi.Sign() == 0 {
+				return nil, errValueNotAllowed
+			}
+			m := new(big.Int).Rem(xi, yi)
+
+			return new(big.Float).SetPrec(r.prec).SetInt(m), nil
+		})
+	}
+
 	return r.binaryOp(func(x, y float64) (float64, error) {
 		if y <= 0.0 {
 			return 0.0, errValueNotAllowed
@@ -71,3 +271,39 @@ func opModulus(r *RpnCalc, _ string) error {
 		return float64(m), nil
 	})
 }
+
+// bigPow calculates x**y for an integer y using repeated squaring
+func bigPow(x, y *big.Float, prec uint) (*big.Float, error) {
+	n, acc := y.Int64()
+	if acc != big.Exact {
+		return nil, errOverflow
+	}
+
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+
+	z := new(big.Float).SetPrec(prec).SetInt64(1)
+	p := new(big.Float).SetPrec(prec).Set(x)
+	for n > 0 {
+		if n&1 == 1 {
+			z.Mul(z, p)
+		}
+		p.Mul(p, p)
+		n >>= 1
+	}
+
+	if z.IsInf() {
+		return nil, errOverflow
+	}
+
+	if neg {
+		if z.Sign() == 0 {
+			return nil, errDivisionByZero
+		}
+		z.Quo(new(big.Float).SetPrec(prec).SetInt64(1), z)
+	}
+
+	return z, nil
+}
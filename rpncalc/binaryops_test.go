@@ -30,7 +30,7 @@ func TestBinaryOp(t *testing.T) {
 		if err != c.err {
 			t.Errorf("Expected result %v, but got %v", c.err, err)
 		}
-		if r.Stack()[0] != c.val {
+		if r.Stack()[0].Float64() != c.val {
 			t.Errorf("Expected value %v, but got %v", c.val, r.Stack()[0])
 		}
 	}
@@ -76,8 +76,8 @@ func TestBinaryOpBasicOps(t *testing.T) {
 
 	for _, c := range cases {
 		r := New()
-		r.stack[1] = c.x
-		r.stack[0] = c.y
+		r.stack[1] = NewFloat(c.x)
+		r.stack[0] = NewFloat(c.y)
 
 		err := c.f(r, "")
 
@@ -90,7 +90,7 @@ func TestBinaryOpBasicOps(t *testing.T) {
 			continue
 		}
 
-		got := r.stack[0]
+		got := r.stack[0].Float64()
 
 		if !almostEqual(got, c.exp) {
 			t.Errorf("%q: Expected result %v, but got %v", c.name, c.exp, got)
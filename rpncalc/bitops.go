@@ -0,0 +1,210 @@
+// Package rpncalc bitwise and shift operators
+package rpncalc
+
+import (
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// bitPattern returns v as an unsigned bit pattern of the word size. Values
+// that are not integers are rejected instead of truncated.
+func (r *RpnCalc) bitPattern(v Value) (uint64, error) {
+	if v.IsComplex() || v.IsTime() || (v.IsRat() && !v.q.IsInt()) {
+		return 0, errNotInteger
+	}
+	if v.u != nil {
+		return 0, errUnits
+	}
+	if v.IsBig() {
+		if !v.b.IsInt() {
+			return 0, errNotInteger
+		}
+	} else {
+		f := v.Float64()
+		if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+			return 0, errNotInteger
+		}
+	}
+
+	x, _ := r.integer(v)
+	if x.Sign() < 0 {
+		x.Add(x, new(big.Int).Lsh(big.NewInt(1), uint(r.wordSize)))
+	}
+	return x.Uint64(), nil
+}
+
+// patternValue converts a bit pattern to a value, signed or unsigned, and
+// keeps patterns too large for a float64 exact
+func (r *RpnCalc) patternValue(u uint64) Value {
+	return r.intResult(r.wrap(new(big.Int).SetUint64(u)))
+}
+
+// mask returns a bit pattern with all bits of the word size set
+func (r *RpnCalc) mask() uint64 {
+	return math.MaxUint64 >> uint(64-r.wordSize)
+}
+
+// bitIndex returns v as a bit index, or shift count, below limit
+func (r *RpnCalc) bitIndex(v Value, limit int) (uint, error) {
+	f := v.Float64()
+	if v.IsComplex() || f != math.Trunc(f) {
+		return 0, errNotInteger
+	}
+	if f < 0 || f >= float64(limit) {
+		return 0, errValueNotAllowed
+	}
+	return uint(f), nil
+}
+
+// unaryBitOp replaces x with f applied to its bit pattern
+func (r *RpnCalc) unaryBitOp(f func(uint64) uint64) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+
+	x, err := r.bitPattern(r.stack[0])
+	if err != nil {
+		return err
+	}
+
+	r.setX(r.patternValue(f(x) & r.mask()))
+	return nil
+}
+
+// binaryBitOp replaces y and x with f applied to their bit patterns
+func (r *RpnCalc) binaryBitOp(f func(uint64, uint64) uint64) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+
+	y, err := r.bitPattern(r.stack[1])
+	if err != nil {
+		return err
+	}
+	x, err := r.bitPattern(r.stack[0])
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(r.patternValue(f(y, x) & r.mask()))
+	return nil
+}
+
+// shiftBitOp replaces y and x with f applied to the bit pattern of y and
+// the count x, which must be a valid index of the word size or, when
+// full is true, the word size itself
+func (r *RpnCalc) shiftBitOp(f func(uint64, uint) uint64, full bool) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+
+	y, err := r.bitPattern(r.stack[1])
+	if err != nil {
+		return err
+	}
+	limit := r.wordSize
+	if full {
+		limit++
+	}
+	n, err := r.bitIndex(r.stack[0], limit)
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(r.patternValue(f(y, n) & r.mask()))
+	return nil
+}
+
+func opBitAnd(r *RpnCalc, _ string) error {
+	return r.binaryBitOp(func(y, x uint64) uint64 {
+		return y & x
+	})
+}
+
+func opBitOr(r *RpnCalc, _ string) error {
+	return r.binaryBitOp(func(y, x uint64) uint64 {
+		return y | x
+	})
+}
+
+func opBitXor(r *RpnCalc, _ string) error {
+	return r.binaryBitOp(func(y, x uint64) uint64 {
+		return y ^ x
+	})
+}
+
+func opBitNot(r *RpnCalc, _ string) error {
+	return r.unaryBitOp(func(x uint64) uint64 {
+		return ^x
+	})
+}
+
+func opShiftLeft(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		return y << n
+	}, true)
+}
+
+func opShiftRight(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		return y >> n
+	}, true)
+}
+
+func opArithmeticShiftRight(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		z := y >> n
+		if y>>uint(r.wordSize-1) == 1 {
+			// fill with the sign bit
+			z |= r.mask() &^ (r.mask() >> n)
+		}
+		return z
+	}, true)
+}
+
+func opRotateLeft(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		size := uint(r.wordSize)
+		return y<<n | y>>(size-n)
+	}, false)
+}
+
+func opRotateRight(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		size := uint(r.wordSize)
+		return y>>n | y<<(size-n)
+	}, false)
+}
+
+func opBitSet(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		return y | 1<<n
+	}, false)
+}
+
+func opBitClear(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		return y &^ (1 << n)
+	}, false)
+}
+
+func opBitTest(r *RpnCalc, _ string) error {
+	return r.shiftBitOp(func(y uint64, n uint) uint64 {
+		return y >> n & 1
+	}, false)
+}
+
+func opPopCount(r *RpnCalc, _ string) error {
+	return r.unaryBitOp(func(x uint64) uint64 {
+		return uint64(bits.OnesCount64(x))
+	})
+}
+
+func opLeadingZeros(r *RpnCalc, _ string) error {
+	return r.unaryBitOp(func(x uint64) uint64 {
+		return uint64(bits.LeadingZeros64(x) - (64 - r.wordSize))
+	})
+}
@@ -0,0 +1,93 @@
+package rpncalc
+
+import "testing"
+
+func TestBitwiseOps(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		err   error
+	}{
+		{"and", "0b1100 0b1010 and", 0b1000, nil},
+		{"or", "0b1100 0b1010 or", 0b1110, nil},
+		{"xor", "0b1100 0b1010 xor", 0b0110, nil},
+		{"not signed", "0 not", -1, nil},
+		{"not unsigned 8 bits", "ws8 unsigned 0x0f not", 0xf0, nil},
+		{"not negative", "-1 not", 0, nil},
+		{"and negative", "-1 0xff and", 0xff, nil},
+		{"shift left", "1 4 shl", 16, nil},
+		{"shift left out of word", "ws8 unsigned 0x81 1 shl", 2, nil},
+		{"shift left all bits", "ws8 1 8 shl", 0, nil},
+		{"shift left into sign bit", "ws8 1 7 shl", -128, nil},
+		{"shift right", "16 4 shr", 1, nil},
+		{"shift right is logical", "ws8 -128 1 shr", 64, nil},
+		{"arithmetic shift right", "ws8 -128 1 sar", -64, nil},
+		{"arithmetic shift right positive", "ws8 64 2 ashr", 16, nil},
+		{"arithmetic shift right all bits", "ws8 -1 8 sar", -1, nil},
+		{"rotate left", "ws8 unsigned 0x81 1 rol", 0x03, nil},
+		{"rotate right", "ws8 unsigned 0x81 1 ror", 0xc0, nil},
+		{"rotate zero bits", "ws8 unsigned 0x81 0 ror", 0x81, nil},
+		{"rotate word size fails", "ws8 1 8 rol", 0, errValueNotAllowed},
+		{"bit set", "0 3 bset", 8, nil},
+		{"bit clear", "15 0 bclr", 14, nil},
+		{"bit test set", "8 3 btest", 1, nil},
+		{"bit test clear", "8 2 btest", 0, nil},
+		{"bit index outside word fails", "ws16 1 16 bset", 0, errValueNotAllowed},
+		{"negative bit index fails", "1 -1 bset", 0, errValueNotAllowed},
+		{"popcount", "0xff popcnt", 8, nil},
+		{"popcount negative", "ws16 -1 popcnt", 16, nil},
+		{"leading zeros", "ws16 1 clz", 15, nil},
+		{"leading zeros of zero", "ws32 0 clz", 32, nil},
+		{"non-integer fails", "1.5 1 and", 0, errNotInteger},
+		{"non-integer count fails", "1 1.5 shl", 0, errNotInteger},
+		{"precision mode", "big128 0xff 0x0f and", 0x0f, nil},
+		{"precision mode non-integer fails", "big128 2.5 not", 0, errNotInteger},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if c.err == nil && r.Val().Float64() != c.exp {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, r.Val())
+		}
+	}
+}
+
+func TestBitwiseOps64(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"and largest signed", "0x7fffffffffffffff 1 and", "0x1"},
+		{"and largest unsigned", "unsigned 0xffffffffffffffff 1 and", "0x1"},
+		{"and keeps low bits", "0x7fffffffffffffff 0x20000000000001 and", "0x20000000000001"},
+		{"or beyond 2^53", "0x7ffffffffffffff0 0xf or", "0x7fffffffffffffff"},
+		{"xor beyond 2^53", "unsigned 0xffffffffffffffff 1 xor", "0xfffffffffffffffe"},
+		{"not beyond 2^53", "unsigned 1 not", "0xfffffffffffffffe"},
+		{"shift left beyond 2^53", "0x20000000000001 4 shl", "0x200000000000010"},
+		{"rotate right", "unsigned 1 1 ror", "0x8000000000000000"},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		if err := r.Evaluate(c.input + " hex"); err != nil {
+			t.Errorf("%q: Unexpected error %v", c.name, err)
+			continue
+		}
+
+		if s := r.Format(r.Val(), 0); s != c.exp {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, s)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+// Package rpncalc complex number operations
+package rpncalc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// SetComplex sets if operations on real values outside their real domain,
+// like the square root of a negative value, give complex results
+func (r *RpnCalc) SetComplex(on bool) {
+	r.complexResults = on
+}
+
+// SetPolar sets if complex values are formatted in polar form
+func (r *RpnCalc) SetPolar(on bool) {
+	r.polar = on
+}
+
+func opComplexMode(r *RpnCalc, _ string) error {
+	r.SetComplex(true)
+	return nil
+}
+
+func opRealMode(r *RpnCalc, _ string) error {
+	r.SetComplex(false)
+	return nil
+}
+
+func opPolarMode(r *RpnCalc, _ string) error {
+	r.SetPolar(true)
+	return nil
+}
+
+func opRectangularMode(r *RpnCalc, _ string) error {
+	r.SetPolar(false)
+	return nil
+}
+
+// polarText formats a complex value as magnitude and angle in the
+// current angle mode
+func (r *RpnCalc) polarText(v Value, prec int) string {
+	c := v.Complex()
+	return fmt.Sprintf("%.*f∠%.*f", prec, cmplx.Abs(c), prec, r.fromRadians(cmplx.Phase(c)))
+}
+
+// complexValue converts a complex result to a value, results without an
+// imaginary part become real values in the current mode
+func (r *RpnCalc) complexValue(c complex128) (Value, error) {
+	if cmplx.IsNaN(c) {
+		return Value{}, errNaN
+	}
+	if cmplx.IsInf(c) {
+		return Value{}, errOverflow
+	}
+	if imag(c) == 0 {
+		return r.value(real(c))
+	}
+	return NewComplex(c), nil
+}
+
+// complexArgs returns true if any of the first n values on the stack is
+// complex, or if complex results are enabled and outside returns true
+// for the real value x
+func (r *RpnCalc) complexArgs(n int, outside func(x float64) bool) bool {
+	for i := 0; i < n && i < len(r.stack); i++ {
+		if r.stack[i].IsComplex() {
+			return true
+		}
+	}
+	if !r.complexResults || outside == nil || len(r.stack) < 1 {
+		return false
+	}
+	return outside(r.stack[0].Float64())
+}
+
+// notComplex returns errComplex if any of the first n values is complex
+func (r *RpnCalc) notComplex(n int) error {
+	for i := 0; i < n && i < len(r.stack); i++ {
+		if r.stack[i].IsComplex() {
+			return errComplex
+		}
+	}
+	return nil
+}
+
+func (r *RpnCalc) complexUnaryOp(f func(complex128) (complex128, error)) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.noUnits(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[0].Complex())
+	if err != nil {
+		return err
+	}
+
+	v, err := r.complexValue(z)
+	if err != nil {
+		return err
+	}
+
+	r.setX(v)
+	return nil
+}
+
+func (r *RpnCalc) complexBinaryOp(f func(complex128, complex128) (complex128, error)) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.noUnits(2); err != nil {
+		return err
+	}
+	if err := r.noTimes(2); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[1].Complex(), r.stack[0].Complex())
+	if err != nil {
+		return err
+	}
+
+	v, err := r.complexValue(z)
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(v)
+	return nil
+}
+
+// complexFunc adapts a math/cmplx function to a complex operation
+func complexFunc(f func(complex128) complex128) func(complex128) (complex128, error) {
+	return func(x complex128) (complex128, error) {
+		return f(x), nil
+	}
+}
+
+// toComplexRadians converts a complex angle in the current angle mode
+func (r *RpnCalc) toComplexRadians(x complex128) complex128 {
+	return x * complex(r.toRadians(1), 0)
+}
+
+// fromComplexRadians converts a complex angle to the current angle mode
+func (r *RpnCalc) fromComplexRadians(x complex128) complex128 {
+	return x * complex(r.fromRadians(1), 0)
+}
+
+// outsideUnit is true for real values outside -1 to 1
+func outsideUnit(x float64) bool {
+	return x < -1 || x > 1
+}
+
+// negative is true for negative real values
+func negative(x float64) bool {
+	return x < 0
+}
+
+func opRect(r *RpnCalc, _ string) error {
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+
+	return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+		return complex(real(y), real(x)), nil
+	})
+}
+
+func opPolar(r *RpnCalc, _ string) error {
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+
+	return r.complexBinaryOp(func(y, x complex128) (complex128, error) {
+		return cmplx.Rect(real(y), r.toRadians(real(x))), nil
+	})
+}
+
+func opRealPart(r *RpnCalc, _ string) error {
+	if !r.complexArgs(1, nil) {
+		return nil
+	}
+
+	return r.complexUnaryOp(func(x complex128) (complex128, error) {
+		return complex(real(x), 0), nil
+	})
+}
+
+func opImaginaryPart(r *RpnCalc, _ string) error {
+	if !r.complexArgs(1, nil) {
+		r.setX(r.zero())
+		return nil
+	}
+
+	return r.complexUnaryOp(func(x complex128) (complex128, error) {
+		return complex(imag(x), 0), nil
+	})
+}
+
+func opAbs(r *RpnCalc, _ string) error {
+	if r.unitArgs(1) {
+		return r.keepUnitOp(opAbs, "")
+	}
+
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return complex(cmplx.Abs(x), 0), nil
+		})
+	}
+
+	if r.ratArgs(1) {
+		return r.ratUnaryOp(func(x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Abs(x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigUnaryOp(func(x *big.Float) (*big.Float, error) {
+			return r.newBig().Abs(x), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return math.Abs(x), nil
+	})
+}
+
+func opArg(r *RpnCalc, _ string) error {
+	return r.complexUnaryOp(func(x complex128) (complex128, error) {
+		if x == 0 {
+			return 0, errDomain
+		}
+		return complex(r.fromRadians(cmplx.Phase(x)), 0), nil
+	})
+}
+
+func opConjugate(r *RpnCalc, _ string) error {
+	return r.complexUnaryOp(complexFunc(cmplx.Conj))
+}
+
+// complexPowArgs is true when y**x needs complex math, for complex
+// operands or, with complex results, a negative y and a fractional x
+func (r *RpnCalc) complexPowArgs() bool {
+	if r.complexArgs(2, nil) {
+		return true
+	}
+	if !r.complexResults || len(r.stack) < 2 {
+		return false
+	}
+	x := r.stack[0].Float64()
+	return r.stack[1].Float64() < 0 && x != math.Trunc(x)
+}
@@ -0,0 +1,84 @@
+package rpncalc
+
+import (
+	"math/cmplx"
+	"testing"
+)
+
+func TestComplexOps(t *testing.T) {
+
+	cases := []struct {
+		name    string
+		input   string
+		complex bool // enable complex results
+		exp     complex128
+		err     error
+	}{
+		{"literal", "3+4i", false, 3 + 4i, nil},
+		{"imaginary literal", "-2i", false, -2i, nil},
+		{"zero imaginary part is real", "3+0i", false, 3, nil},
+		{"rect", "3 4 rect", false, 3 + 4i, nil},
+		{"polar", "deg 2 90 polar", false, 2i, nil},
+		{"add", "3+4i 1-2i +", false, 4 + 2i, nil},
+		{"add real", "3+4i 1 +", false, 4 + 4i, nil},
+		{"subtract to real", "3+4i 4i -", false, 3, nil},
+		{"multiply", "3+4i 1-2i *", false, 11 - 2i, nil},
+		{"divide", "11-2i 1-2i /", false, 3 + 4i, nil},
+		{"divide by zero", "3+4i 0 /", false, 0, errDivisionByZero},
+		{"power", "1i 2 pow", false, -1, nil},
+		{"negate", "3+4i neg", false, -3 - 4i, nil},
+		{"inverse", "1i inv", false, -1i, nil},
+		{"square", "1+1i sq", false, 2i, nil},
+		{"square root", "-3+4i sqrt", false, 1 + 2i, nil},
+		{"square root of negative", "-4 sqrt", false, 0, errNaN},
+		{"square root of negative complex", "-4 sqrt", true, 2i, nil},
+		{"ln of negative", "-1 ln", true, complex(0, 3.141592653589793), nil},
+		{"ln of negative without complex", "-1 ln", false, 0, errDomain},
+		{"ln of zero", "0 ln", true, 0, errDomain},
+		{"fractional power of negative", "-8 0.5 pow", true, cmplx.Pow(-8, 0.5), nil},
+		{"asin outside unit", "2 asin", true, cmplx.Asin(2), nil},
+		{"exp", "pi 1i * exp", false, -1, nil},
+		{"sine in degrees", "deg 90 1i * sin", false, cmplx.Sin(complex(0, 1.5707963267948966)), nil},
+		{"real part", "3+4i re", false, 3, nil},
+		{"imaginary part", "3+4i im", false, 4, nil},
+		{"abs", "3+4i abs", false, 5, nil},
+		{"abs real", "-3 abs", false, 3, nil},
+		{"arg", "deg 1+1i arg", false, 45, nil},
+		{"conjugate", "3+4i conj", false, 3 - 4i, nil},
+		{"rect of complex fails", "1i 1 rect", false, 0, errComplex},
+		{"real operation fails", "3+4i 1 mod", false, 0, errComplex},
+		{"comparison fails", "3+4i 1 <", false, 0, errComplex},
+		{"bitwise fails", "3+4i 1 and", false, 0, errNotInteger},
+		{"precision mode", "big128 3+4i 1 +", false, 4 + 4i, nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetComplex(c.complex)
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if z := r.Val().Complex(); c.err == nil && cmplx.Abs(z-c.exp) > 1e-12 {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, z)
+		}
+	}
+}
+
+func TestFormatComplex(t *testing.T) {
+	r := New()
+	v := NewComplex(1 + 1i)
+
+	if s := r.Format(v, 2); s != "1.00+1.00i" {
+		t.Errorf("Expected rectangular form, but got %q", s)
+	}
+
+	r.SetPolar(true)
+	r.SetAngleMode(Degrees)
+	if s := r.Format(v, 2); s != "1.41∠45.00" {
+		t.Errorf("Expected polar form, but got %q", s)
+	}
+}
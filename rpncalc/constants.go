@@ -1,7 +1,10 @@
 // Package rpncalc operators
 package rpncalc
 
-import "math"
+import (
+	"math"
+	"math/big"
+)
 
 // Constant type storing names, value and description of a constant
 type Constant struct {
@@ -33,6 +36,14 @@ var constants = []Constant{
 	// TODO: add constants
 }
 
+// Decimal expansions used for the math constants in precision mode
+var constantDigits = map[string]string{
+	"e":   "2.71828182845904523536028747135266249775724709369995957496696762772407663035354759457138217852516642742746",
+	"phi": "1.61803398874989484820458683436563811772030917980576286213544862270526046281890244970720720418939113748475",
+	"pi":  "3.14159265358979323846264338327950288419716939937510582097494459230781640628620899862803482534211706798214",
+	"tau": "6.28318530717958647692528676655900576839433879875021164194988918461563281257241799725606965068423413596428",
+}
+
 // Constants returns a string float map of constant name and value.
 func Constants() []Constant {
 	return constants
@@ -43,7 +54,7 @@ func (r *RpnCalc) pushConstant(name string) bool {
 	for _, c := range constants {
 		for _, n := range c.Names {
 			if n == name {
-				r.stack = enter(r.stack, c.Value)
+				r.push(r.constValue(c))
 				return true
 			}
 		}
@@ -51,3 +62,29 @@ func (r *RpnCalc) pushConstant(name string) bool {
 
 	return false
 }
+
+// isConstant returns true if name is the name of a constant
+func isConstant(name string) bool {
+	for _, c := range constants {
+		if in(name, c.Names...) {
+			return true
+		}
+	}
+	return false
+}
+
+// constValue returns the value of a constant in the current mode
+func (r *RpnCalc) constValue(c Constant) Value {
+	if !r.precise() {
+		return NewFloat(c.Value)
+	}
+
+	if digits, ok := constantDigits[c.Names[0]]; ok {
+		b, _, err := big.ParseFloat(digits, 10, r.prec, big.ToNearestEven)
+		if err == nil {
+			return NewBig(b)
+		}
+	}
+
+	return NewBig(new(big.Float).SetPrec(r.prec).SetFloat64(c.Value))
+}
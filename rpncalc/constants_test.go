@@ -22,7 +22,7 @@ func TestConstants(t *testing.T) {
 		if found != c.found {
 			t.Errorf("Expected result %v, but got %v", c.found, found)
 		}
-		if r.Stack()[0] != c.exp {
+		if r.Stack()[0].Float64() != c.exp {
 			t.Errorf("Expected value %v, but got %v", c.exp, r.Stack()[0])
 		}
 	}
@@ -0,0 +1,239 @@
+// Package rpncalc control flow
+package rpncalc
+
+import "math/big"
+
+// Keywords used by definitions and control structures, they can not be
+// used as names of words
+var keywords = []string{":", ";", "forget", "if", "else", "then", "times", "while", "do", "end", "to", "tz", "zone"}
+
+func isKeyword(t string) bool {
+	return in(t, keywords...)
+}
+
+// control evaluates a control structure at the start of ts and returns the
+// number of tokens used. The structures are
+//
+//	cond if tokens then
+//	cond if tokens else tokens then
+//	n times tokens end
+//	while cond-tokens do tokens end
+func (r *RpnCalc) control(ts []string) (int, error) {
+	switch ts[0] {
+	case "if":
+		return r.controlIf(ts)
+	case "times":
+		return r.controlTimes(ts)
+	case "while":
+		return r.controlWhile(ts)
+	}
+	return 0, errInvalidControl
+}
+
+func (r *RpnCalc) controlIf(ts []string) (int, error) {
+	end, err := scanBlock(ts, 1, "else", "then")
+	if err != nil {
+		return 0, err
+	}
+	yes, no := ts[1:end], []string{}
+
+	if ts[end] == "else" {
+		els := end
+		end, err = scanBlock(ts, els+1, "then")
+		if err != nil {
+			return 0, err
+		}
+		no = ts[els+1 : end]
+	}
+
+	if err := r.need(1); err != nil {
+		return 0, err
+	}
+	if !r.pop().IsZero() {
+		return end + 1, r.call(yes)
+	}
+	return end + 1, r.call(no)
+}
+
+func (r *RpnCalc) controlTimes(ts []string) (int, error) {
+	end, err := scanBlock(ts, 1, "end")
+	if err != nil {
+		return 0, err
+	}
+
+	if err := r.need(1); err != nil {
+		return 0, err
+	}
+	n := r.stack[0].Float64()
+	if n < 0 || n != float64(int64(n)) {
+		return 0, errValueNotAllowed
+	}
+	r.pop()
+
+	for i := int64(0); i < int64(n); i++ {
+		if err := r.step(); err != nil {
+			return 0, err
+		}
+		if err := r.call(ts[1:end]); err != nil {
+			return 0, err
+		}
+	}
+
+	return end + 1, nil
+}
+
+func (r *RpnCalc) controlWhile(ts []string) (int, error) {
+	do, err := scanBlock(ts, 1, "do")
+	if err != nil {
+		return 0, err
+	}
+	end, err := scanBlock(ts, do+1, "end")
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		if err := r.step(); err != nil {
+			return 0, err
+		}
+		if err := r.call(ts[1:do]); err != nil {
+			return 0, err
+		}
+		if err := r.need(1); err != nil {
+			return 0, err
+		}
+		if r.pop().IsZero() {
+			break
+		}
+		if err := r.call(ts[do+1 : end]); err != nil {
+			return 0, err
+		}
+	}
+
+	return end + 1, nil
+}
+
+// scanBlock returns the index of the first of the ends tokens, from index
+// i in ts, that is not inside a nested control structure
+func scanBlock(ts []string, i int, ends ...string) (int, error) {
+	depth := 0
+	for ; i < len(ts); i++ {
+		t := ts[i]
+		if depth == 0 && in(t, ends...) {
+			return i, nil
+		}
+
+		switch t {
+		case "if", "times", "while":
+			depth++
+		case "then", "end":
+			depth--
+		}
+
+		if depth < 0 {
+			break
+		}
+	}
+
+	return 0, errInvalidControl
+}
+
+// Comparison and boolean operators, true is one and false is zero
+
+func boolVal(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0.0
+}
+
+func (r *RpnCalc) compareOp(f func(int) bool) error {
+	if r.timeArgs(2) {
+		return r.timeCompare(f)
+	}
+
+	if r.unitArgs(2) {
+		return r.sameUnitOp(func(r *RpnCalc, _ string) error {
+			return r.compareOp(f)
+		}, "", false)
+	}
+
+	if r.precise() {
+		return r.bigBinaryOp(func(x, y *big.Float) (*big.Float, error) {
+			return r.newBig().SetFloat64(boolVal(f(x.Cmp(y)))), nil
+		})
+	}
+
+	return r.binaryOp(func(x, y float64) (float64, error) {
+		switch {
+		case x < y:
+			return boolVal(f(-1)), nil
+		case x > y:
+			return boolVal(f(1)), nil
+		case x == y:
+			return boolVal(f(0)), nil
+		}
+		return 0.0, nil // NaN is never compared true
+	})
+}
+
+func opLess(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c < 0 })
+}
+
+func opLessOrEqual(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c <= 0 })
+}
+
+func opGreater(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c > 0 })
+}
+
+func opGreaterOrEqual(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c >= 0 })
+}
+
+func opEqual(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c == 0 })
+}
+
+func opNotEqual(r *RpnCalc, _ string) error {
+	return r.compareOp(func(c int) bool { return c != 0 })
+}
+
+func (r *RpnCalc) boolOp(f func(bool, bool) bool) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+
+	x, y := !r.stack[1].IsZero(), !r.stack[0].IsZero()
+	v, err := r.value(boolVal(f(x, y)))
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(v)
+	return nil
+}
+
+func opAnd(r *RpnCalc, _ string) error {
+	return r.boolOp(func(x, y bool) bool { return x && y })
+}
+
+func opOr(r *RpnCalc, _ string) error {
+	return r.boolOp(func(x, y bool) bool { return x || y })
+}
+
+func opNot(r *RpnCalc, _ string) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+
+	v, err := r.value(boolVal(r.stack[0].IsZero()))
+	if err != nil {
+		return err
+	}
+	r.setX(v)
+	return nil
+}
@@ -0,0 +1,118 @@
+package rpncalc
+
+import "testing"
+
+func TestControl(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input []string
+		stack []float64
+		err   error
+	}{
+		// Comparisons and booleans
+		{"less", []string{"1 2 <"}, []float64{1, 0, 0, 0}, nil},
+		{"not less", []string{"2 1 lt"}, []float64{0, 0, 0, 0}, nil},
+		{"less or equal", []string{"2 2 <="}, []float64{1, 0, 0, 0}, nil},
+		{"greater", []string{"3 2 >"}, []float64{1, 0, 0, 0}, nil},
+		{"greater or equal", []string{"1 2 >="}, []float64{0, 0, 0, 0}, nil},
+		{"equal", []string{"2 2 =="}, []float64{1, 0, 0, 0}, nil},
+		{"not equal", []string{"2 2 !="}, []float64{0, 0, 0, 0}, nil},
+		{"big equal", []string{"big128 0.1 0.2 + 0.3 =="}, []float64{1, 0, 0, 0}, nil},
+		{"and", []string{"1 2 &&"}, []float64{1, 0, 0, 0}, nil},
+		{"and false", []string{"1 0 &&"}, []float64{0, 0, 0, 0}, nil},
+		{"or", []string{"0 3 ||"}, []float64{1, 0, 0, 0}, nil},
+		{"or false", []string{"0 0 ||"}, []float64{0, 0, 0, 0}, nil},
+		{"not", []string{"0 !"}, []float64{1, 0, 0, 0}, nil},
+
+		// Conditionals
+		{"if true", []string{"5 1 if 1 + then"}, []float64{6, 0, 0, 0}, nil},
+		{"if false", []string{"5 0 if 1 + then"}, []float64{5, 0, 0, 0}, nil},
+		{"if else true", []string{"5 3 2 > if 1 else 2 then"}, []float64{1, 5, 0, 0}, nil},
+		{"if else false", []string{"5 3 2 < if 1 else 2 then"}, []float64{2, 5, 0, 0}, nil},
+		{"nested if", []string{"1 1 if 0 if 7 else 8 then else 9 then"}, []float64{8, 1, 0, 0}, nil},
+		{"if without then", []string{"1 if 2"}, []float64{0, 0, 0, 0}, errInvalidControl},
+		{"stray then", []string{"1 then"}, []float64{0, 0, 0, 0}, errInvalidControl},
+
+		// Loops
+		{"times", []string{"0 5 times 2 + end"}, []float64{10, 0, 0, 0}, nil},
+		{"zero times", []string{"1 0 times 2 + end"}, []float64{1, 0, 0, 0}, nil},
+		{"negative times", []string{"-1 times end"}, []float64{0, 0, 0, 0}, errValueNotAllowed},
+		{"nested times", []string{"0 3 times 2 times 1 + end end"}, []float64{6, 0, 0, 0}, nil},
+		{"times with if", []string{"0 4 times 1 + end 4 == if 42 then"}, []float64{42, 0, 0, 0}, nil},
+		{"while", []string{"1 while rs1 rr1 100 < do 2 * end"}, []float64{128, 0, 0, 0}, nil},
+		{"while never", []string{"7 while 0 do 1 + end"}, []float64{7, 0, 0, 0}, nil},
+		{"while without do", []string{"while 1 end"}, []float64{0, 0, 0, 0}, errInvalidControl},
+
+		// Words
+		{"word with if", []string{": abs rs1 rr1 0 < if neg then ;", "-3 abs"}, []float64{3, 0, 0, 0}, nil},
+		{"word with loop", []string{": pow2 1 sw times 2 * end ;", "10 pow2"}, []float64{1024, 0, 0, 0}, nil},
+
+		// Step limit
+		{"endless loop", []string{"while 1 do end"}, []float64{0, 0, 0, 0}, errStepLimit},
+		{"endless loop in word", []string{": spin while 1 do 1 end ;", "spin"}, []float64{0, 0, 0, 0}, errStepLimit},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetStepLimit(10000)
+
+		var err error
+		for _, l := range c.input {
+			err = r.Evaluate(l)
+		}
+
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		for i := range c.stack {
+			if c.stack[i] != r.stack[i].Float64() {
+				t.Errorf("%q: Expected stack %v, but got %v", c.name, c.stack, r.stack)
+				break
+			}
+		}
+	}
+}
+
+func TestControlLogAndReplay(t *testing.T) {
+
+	r := New()
+
+	if err := r.Evaluate("0 3 times 2 + end"); err != nil {
+		t.Fatalf("Failed to evaluate loop, got error %v", err)
+	}
+
+	exp := "0 3 times 2 + end\n"
+	if Script(r.Log()) != exp {
+		t.Errorf("Expected script %q, but got %q", exp, Script(r.Log()))
+	}
+
+	replayed := New()
+	if err := replayed.Replay(r.Log()); err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+	if replayed.Val().Float64() != 6 {
+		t.Errorf("Expected value 6, but got %v", replayed.Val())
+	}
+}
+
+func TestStepLimit(t *testing.T) {
+
+	r := New()
+
+	r.SetStepLimit(5)
+	if err := r.Evaluate("1 2 3 4 5 6"); err != errStepLimit {
+		t.Errorf("Expected error %v, but got %v", errStepLimit, err)
+	}
+
+	r.SetStepLimit(0)
+	if err := r.Evaluate("0 100000 times end"); err != nil {
+		t.Errorf("Expected no limit, but got error %v", err)
+	}
+
+	if r.Settings().StepLimit != 0 {
+		t.Errorf("Expected step limit 0, but got %v", r.Settings().StepLimit)
+	}
+}
@@ -0,0 +1,348 @@
+// Package rpncalc integer and number theory operations
+package rpncalc
+
+import (
+	"math"
+	"math/big"
+)
+
+const (
+	// maxFactors is the largest number of factors multiplied by fact,
+	// ncr and npr, 100000! has almost half a million digits
+	maxFactors = 100000
+
+	// maxFactorBits is the size of the largest number that is factorized
+	maxFactorBits = 64
+)
+
+// bigInt returns v as an integer, values that are not integers are rejected
+// instead of truncated
+func bigInt(v Value) (*big.Int, error) {
+	q := v.Rat()
+	if q == nil || !q.IsInt() {
+		return nil, errNotInteger
+	}
+	return new(big.Int).Set(q.Num()), nil
+}
+
+// intArgs returns the first n values on the stack as integers, the deepest
+// value first
+func (r *RpnCalc) intArgs(n int) ([]*big.Int, error) {
+	if err := r.need(n); err != nil {
+		return nil, err
+	}
+	if err := r.realArgs(n); err != nil {
+		return nil, err
+	}
+
+	xs := make([]*big.Int, n)
+	for i := 0; i < n; i++ {
+		x, err := bigInt(r.stack[n-1-i])
+		if err != nil {
+			return nil, err
+		}
+		xs[i] = x
+	}
+	return xs, nil
+}
+
+// intResult converts an integer result to a value. In float64 mode integers
+// too large for a float64 are kept as exact integers instead of rounded.
+func (r *RpnCalc) intResult(x *big.Int) Value {
+	if r.precise() || x.BitLen() <= 53 {
+		return r.intValue(x)
+	}
+	return NewRat(new(big.Rat).SetInt(x))
+}
+
+// intOp replaces the first n values on the stack with f applied to them as
+// integers
+func (r *RpnCalc) intOp(n int, f func(xs []*big.Int) (*big.Int, error)) error {
+	xs, err := r.intArgs(n)
+	if err != nil {
+		return err
+	}
+
+	z, err := f(xs)
+	if err != nil {
+		return err
+	}
+
+	for i := 1; i < n; i++ {
+		r.pop()
+	}
+	r.setX(r.intResult(z))
+	return nil
+}
+
+// smallInt returns x as an int if it is at most limit, or errOverflow
+func smallInt(x *big.Int, limit int64) (int64, error) {
+	if !x.IsInt64() || x.Int64() > limit {
+		return 0, errOverflow
+	}
+	return x.Int64(), nil
+}
+
+// primeFactors returns the prime factors of n, smallest first
+func primeFactors(n *big.Int) []*big.Int {
+	fs := []*big.Int{}
+	n = new(big.Int).Set(n)
+
+	// small factors by trial division
+	for p := int64(2); p < 1000; p++ {
+		d := big.NewInt(p)
+		for new(big.Int).Rem(n, d).Sign() == 0 {
+			fs = append(fs, d)
+			n.Quo(n, d)
+		}
+	}
+
+	rest := []*big.Int{}
+	if n.Cmp(big.NewInt(1)) > 0 {
+		rest = splitFactors(n)
+	}
+
+	sortInts(rest)
+	return append(fs, rest...)
+}
+
+// splitFactors returns the prime factors of n, which has no small factors
+func splitFactors(n *big.Int) []*big.Int {
+	if n.ProbablyPrime(20) {
+		return []*big.Int{n}
+	}
+
+	d := pollardRho(n)
+	return append(splitFactors(d), splitFactors(new(big.Int).Quo(n, d))...)
+}
+
+// pollardRho returns a non trivial factor of the composite n
+func pollardRho(n *big.Int) *big.Int {
+	one := big.NewInt(1)
+	for c := int64(1); ; c++ {
+		x, y, d := big.NewInt(2), big.NewInt(2), big.NewInt(1)
+		f := func(v *big.Int) {
+			v.Mul(v, v).Add(v, big.NewInt(c)).Mod(v, n)
+		}
+		for d.Cmp(one) == 0 {
+			f(x)
+			f(y)
+			f(y)
+			d.GCD(nil, nil, new(big.Int).Abs(new(big.Int).Sub(x, y)), n)
+		}
+		if d.Cmp(n) != 0 {
+			return d
+		}
+	}
+}
+
+// sortInts sorts a few integers in increasing order
+func sortInts(xs []*big.Int) {
+	for i := 1; i < len(xs); i++ {
+		for j := i; j > 0 && xs[j].Cmp(xs[j-1]) < 0; j-- {
+			xs[j], xs[j-1] = xs[j-1], xs[j]
+		}
+	}
+}
+
+func opGCD(r *RpnCalc, _ string) error {
+	return r.intOp(2, func(xs []*big.Int) (*big.Int, error) {
+		y, x := xs[0].Abs(xs[0]), xs[1].Abs(xs[1])
+		return new(big.Int).GCD(nil, nil, y, x), nil
+	})
+}
+
+func opLCM(r *RpnCalc, _ string) error {
+	return r.intOp(2, func(xs []*big.Int) (*big.Int, error) {
+		y, x := xs[0].Abs(xs[0]), xs[1].Abs(xs[1])
+		if y.Sign() == 0 || x.Sign() == 0 {
+			return new(big.Int), nil
+		}
+		g := new(big.Int).GCD(nil, nil, y, x)
+		return new(big.Int).Mul(new(big.Int).Quo(y, g), x), nil
+	})
+}
+
+// opFactorial calculates x! exactly for integers and with the gamma
+// function for other values
+func opFactorial(r *RpnCalc, _ string) error {
+	if err := r.realArgs(1); err != nil {
+		return err
+	}
+	if _, err := bigInt(r.stack[0]); err != nil {
+		return r.unaryOp(func(x float64, _ string) (float64, error) {
+			g := math.Gamma(x + 1)
+			if math.IsInf(g, 0) {
+				return 0, errOverflow
+			}
+			return g, nil
+		})
+	}
+
+	return r.intOp(1, func(xs []*big.Int) (*big.Int, error) {
+		if xs[0].Sign() < 0 {
+			return nil, errDomain
+		}
+		n, err := smallInt(xs[0], maxFactors)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).MulRange(1, n), nil
+	})
+}
+
+// opCombinations calculates the number of ways to choose x of y items
+func opCombinations(r *RpnCalc, _ string) error {
+	return r.intOp(2, func(xs []*big.Int) (*big.Int, error) {
+		n, k, err := choose(xs[0], xs[1])
+		if err != nil || k > n {
+			return new(big.Int), err
+		}
+		if n-k < k {
+			k = n - k
+		}
+		if k > maxFactors {
+			return nil, errOverflow
+		}
+		return new(big.Int).Binomial(n, k), nil
+	})
+}
+
+// opPermutations calculates the number of ordered ways to choose x of y
+// items
+func opPermutations(r *RpnCalc, _ string) error {
+	return r.intOp(2, func(xs []*big.Int) (*big.Int, error) {
+		n, k, err := choose(xs[0], xs[1])
+		if err != nil || k > n {
+			return new(big.Int), err
+		}
+		if k > maxFactors {
+			return nil, errOverflow
+		}
+		return new(big.Int).MulRange(n-k+1, n), nil
+	})
+}
+
+// choose returns the number of items n and the number chosen k
+func choose(n, k *big.Int) (int64, int64, error) {
+	if n.Sign() < 0 || k.Sign() < 0 {
+		return 0, 0, errDomain
+	}
+	if !n.IsInt64() || !k.IsInt64() {
+		return 0, 0, errOverflow
+	}
+	return n.Int64(), k.Int64(), nil
+}
+
+func opIsPrime(r *RpnCalc, _ string) error {
+	xs, err := r.intArgs(1)
+	if err != nil {
+		return err
+	}
+
+	v, err := r.value(boolVal(xs[0].Sign() > 0 && xs[0].ProbablyPrime(20)))
+	if err != nil {
+		return err
+	}
+
+	r.setX(v)
+	return nil
+}
+
+// opNextPrime calculates the smallest prime larger than x
+func opNextPrime(r *RpnCalc, _ string) error {
+	return r.intOp(1, func(xs []*big.Int) (*big.Int, error) {
+		p := xs[0]
+		if p.Cmp(big.NewInt(2)) < 0 {
+			return big.NewInt(2), nil
+		}
+
+		p.Add(p, big.NewInt(1))
+		for !p.ProbablyPrime(20) {
+			p.Add(p, big.NewInt(1))
+		}
+		return p, nil
+	})
+}
+
+// opFactorize replaces x with its prime factors, the largest factor in
+// pos 0. On a fixed stack it is an overflow if the factors do not fit.
+func opFactorize(r *RpnCalc, _ string) error {
+	xs, err := r.intArgs(1)
+	if err != nil {
+		return err
+	}
+
+	n := xs[0]
+	if n.Sign() < 0 {
+		return errDomain
+	}
+	if n.Cmp(big.NewInt(2)) < 0 {
+		return errValueNotAllowed
+	}
+	if n.BitLen() > maxFactorBits {
+		return errOverflow
+	}
+
+	// the factors replace x and must not push values off a fixed stack
+	fs := primeFactors(n)
+	if !r.dynamic {
+		used := r.depth - 1
+		if used < 0 {
+			used = 0
+		}
+		if len(fs) > len(r.stack)-used {
+			return errOverflow
+		}
+	}
+
+	r.pop()
+	for _, f := range fs {
+		r.push(r.intResult(f))
+	}
+	return nil
+}
+
+// opIntegerDivision divides y by x, truncating towards zero
+func opIntegerDivision(r *RpnCalc, _ string) error {
+	return r.intOp(2, func(xs []*big.Int) (*big.Int, error) {
+		if xs[1].Sign() == 0 {
+			return nil, errDivisionByZero
+		}
+		return new(big.Int).Quo(xs[0], xs[1]), nil
+	})
+}
+
+// opDivMod replaces y and x with the quotient and the remainder of y
+// divided by x, truncating towards zero
+func opDivMod(r *RpnCalc, _ string) error {
+	xs, err := r.intArgs(2)
+	if err != nil {
+		return err
+	}
+	if xs[1].Sign() == 0 {
+		return errDivisionByZero
+	}
+
+	q, m := new(big.Int).QuoRem(xs[0], xs[1], new(big.Int))
+	r.stack[1] = r.intResult(q)
+	r.setX(r.intResult(m))
+	return nil
+}
+
+// opPowMod calculates z to the power of y modulo x, a negative y uses the
+// modular inverse of z
+func opPowMod(r *RpnCalc, _ string) error {
+	return r.intOp(3, func(xs []*big.Int) (*big.Int, error) {
+		if xs[2].Sign() <= 0 {
+			return nil, errValueNotAllowed
+		}
+
+		z := new(big.Int).Exp(xs[0], xs[1], xs[2])
+		if z == nil {
+			// no inverse when z and x have common factors
+			return nil, errDomain
+		}
+		return z, nil
+	})
+}
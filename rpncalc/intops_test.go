@@ -0,0 +1,118 @@
+package rpncalc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntegerOps(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string
+		err   error
+	}{
+		{"gcd", "12 18 gcd", "6", nil},
+		{"gcd negative", "-12 18 gcd", "6", nil},
+		{"gcd zero", "0 5 gcd", "5", nil},
+		{"lcm", "4 6 lcm", "12", nil},
+		{"lcm zero", "0 6 lcm", "0", nil},
+		{"not integer", "4.5 6 lcm", "", errNotInteger},
+		{"fraction not integer", "1/2 6 gcd", "", errNotInteger},
+		{"complex", "2i 6 gcd", "", errComplex},
+		{"factorial", "5 fact", "120", nil},
+		{"factorial zero", "0 fact", "1", nil},
+		{"large factorial", "25 fact", "15511210043330985984000000", nil},
+		{"large factorial is exact", "30 fact 29 fact /", "30", nil},
+		{"large factorial float operation", "1000 fact sqrt", "", errOverflow},
+		{"large factorial exact operation", "1000 fact 999 fact /", "1000", nil},
+		{"factorial gamma", "0.5 fact 1000000 *", "886227", nil},
+		{"factorial negative", "-1 fact", "", errDomain},
+		{"factorial too large", "1e6 fact", "", errOverflow},
+		{"combinations", "52 5 ncr", "2598960", nil},
+		{"large combinations", "100 50 ncr", "100891344545564193334812497256", nil},
+		{"combinations too many", "3 5 ncr", "0", nil},
+		{"combinations negative", "-3 2 ncr", "", errDomain},
+		{"permutations", "10 3 npr", "720", nil},
+		{"permutations all", "5 5 npr", "120", nil},
+		{"is prime", "97 isprime", "1", nil},
+		{"is not prime", "91 isprime", "0", nil},
+		{"one is not prime", "1 isprime", "0", nil},
+		{"next prime", "100 nextprime", "101", nil},
+		{"next prime of prime", "101 nextprime", "103", nil},
+		{"next prime of negative", "-5 nextprime", "2", nil},
+		{"factor largest", "600851475143 factor", "6857", nil},
+		{"factor count", "360 factor depth", "6", nil},
+		{"factor large primes", "big128 1000003 1000033 * factor swap", "1000003", nil},
+		{"factor one", "1 factor", "", errValueNotAllowed},
+		{"factor negative", "-6 factor", "", errDomain},
+		{"integer division", "7 2 idiv", "3", nil},
+		{"integer division negative", "-7 2 idiv", "-3", nil},
+		{"integer division by zero", "7 0 idiv", "", errDivisionByZero},
+		{"divmod remainder", "-7 2 divmod", "-1", nil},
+		{"divmod quotient", "17 5 divmod drop", "3", nil},
+		{"divmod by zero", "7 0 divmod", "", errDivisionByZero},
+		{"powmod", "4 13 497 powmod", "445", nil},
+		{"powmod large", "2 1000 1000007 powmod", "783922", nil},
+		{"powmod negative base", "-3 3 7 powmod", "1", nil},
+		{"powmod inverse", "3 -1 7 powmod", "5", nil},
+		{"powmod no inverse", "2 -1 4 powmod", "", errDomain},
+		{"powmod modulus", "2 3 0 powmod", "", errValueNotAllowed},
+	}
+
+	for _, c := range cases {
+		r := New()
+		if err := r.SetStackSize(0); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if s := r.Val().Text(0); s != c.exp {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestFactorizeFixedStack(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   []string
+		err   error
+	}{
+		{"fits", "12", []string{"3", "2", "2", "0"}, nil},
+		{"fills the stack", "16", []string{"2", "2", "2", "2"}, nil},
+		{"keeps values below", "7 6", []string{"3", "2", "7", "0"}, nil},
+		{"too many factors", "360", []string{"360", "0", "0", "0"}, errOverflow},
+		{"no room below", "1 2 3 6", []string{"6", "3", "2", "1"}, errOverflow},
+	}
+
+	for _, c := range cases {
+		r := New()
+		if err := r.Evaluate(c.input); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		if err := r.Evaluate("factor"); err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+		}
+
+		got := []string{}
+		for _, v := range r.Stack() {
+			got = append(got, v.Text(0))
+		}
+		if strings.Join(got, " ") != strings.Join(c.exp, " ") {
+			t.Errorf("%q: Expected stack %v, but got %v", c.name, c.exp, got)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Package rpncalc calculation log
+package rpncalc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogKind defines what a log entry contains
+type LogKind int
+
+const (
+	// LogInput is an evaluated token, a number, constant or operator
+	LogInput LogKind = iota
+	// LogResult is the value on the stack after an operator
+	LogResult
+	// LogError is an input line that failed and was rolled back
+	LogError
+	// LogComment is a comment line
+	LogComment
+)
+
+// LogEntry is one entry in the calculation log
+type LogEntry struct {
+	Kind LogKind
+	Text string
+	Line int // input line number, tokens from the same line share it
+	Time time.Time
+}
+
+// String formats the log entry for display
+func (e LogEntry) String() string {
+	switch e.Kind {
+	case LogResult:
+		return ">> " + e.Text
+	case LogError:
+		return "[" + e.Text + "]"
+	}
+	return e.Text
+}
+
+// Script creates a script from log entries that reproduces the
+// calculations when evaluated line by line. Results are left out and
+// errors are written as comments.
+func Script(entries []LogEntry) string {
+	lines := []string{}
+	tokens := []string{}
+	line := 0
+
+	flush := func() {
+		if len(tokens) > 0 {
+			lines = append(lines, strings.Join(tokens, " "))
+			tokens = []string{}
+		}
+	}
+
+	for _, e := range entries {
+		switch e.Kind {
+		case LogInput:
+			if e.Line != line {
+				flush()
+				line = e.Line
+			}
+			tokens = append(tokens, e.Text)
+		case LogComment:
+			flush()
+			lines = append(lines, e.Text)
+		case LogError:
+			flush()
+			lines = append(lines, "# error: "+e.Text)
+		}
+	}
+	flush()
+
+	if len(lines) < 1 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Replay resets the modes, stack, registers, variables, words, log and undo
+// history and rebuilds the state by evaluating the script of a log, see
+// Script, line by line. The statistics and time value of money registers
+// are cleared too. Modes are changed with tokens, like deg or stack0, so
+// they are replayed from the log.
+func (r *RpnCalc) Replay(entries []LogEntry) error {
+	r.resetModes()
+	r.ClearStack()
+	r.ClearRegs()
+	r.ClearVars()
+	r.ClearStats()
+	r.ClearTVM()
+	r.ClearLog()
+	r.ClearWords()
+	r.undos = nil
+	r.redos = nil
+
+	for i, l := range strings.Split(Script(entries), "\n") {
+		if err := r.Evaluate(l); err != nil {
+			return fmt.Errorf("replay failed on line %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *RpnCalc) addLog(kind LogKind, text string) {
+	r.log = append(r.log, LogEntry{kind, text, r.lines, time.Now()})
+}
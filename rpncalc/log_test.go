@@ -0,0 +1,163 @@
+package rpncalc
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLogEntries(t *testing.T) {
+
+	r := New()
+
+	for _, l := range []string{"# a comment", "1 pi +", "2 foo", "3 *"} {
+		_ = r.Evaluate(l)
+	}
+
+	exp := []struct {
+		kind LogKind
+		text string
+		line int
+	}{
+		{LogComment, "# a comment", 1},
+		{LogInput, "1", 2},
+		{LogInput, "pi", 2},
+		{LogInput, "+", 2},
+		{LogResult, "4.141592653589793", 2},
+		{LogError, "unknown input: 2 foo", 3},
+		{LogInput, "3", 4},
+		{LogInput, "*", 4},
+		{LogResult, "12.42477796076938", 4},
+	}
+
+	l := r.Log()
+	if len(l) != len(exp) {
+		t.Fatalf("Expected %d log entries, but got %d: %v", len(exp), len(l), l)
+	}
+
+	for i, e := range exp {
+		if l[i].Kind != e.kind || l[i].Text != e.text || l[i].Line != e.line {
+			t.Errorf("Entry %d: Expected %v %q on line %d, but got %v %q on line %d",
+				i, e.kind, e.text, e.line, l[i].Kind, l[i].Text, l[i].Line)
+		}
+		if time.Since(l[i].Time) > time.Minute {
+			t.Errorf("Entry %d: Bad timestamp %v", i, l[i].Time)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+
+	r := New()
+
+	for _, l := range []string{"# a comment", "1 2 +", "3 0 /", "big64 4", "*"} {
+		_ = r.Evaluate(l)
+	}
+
+	exp := "# a comment\n1 2 +\n# error: division by zero: 3 0 /\nbig64 4\n*\n"
+
+	got := Script(r.Log())
+	if got != exp {
+		t.Errorf("Expected script %q, but got %q", exp, got)
+	}
+
+	if Script(nil) != "" {
+		t.Errorf("Expected empty script for empty log, but got %q", Script(nil))
+	}
+}
+
+func TestReplay(t *testing.T) {
+
+	r := New()
+
+	for _, l := range []string{"1 rs1 2", "3 +", "big128 0.1 0.2 +", "5 foo", "undo", "pi"} {
+		_ = r.Evaluate(l)
+	}
+
+	stack := make([]Value, len(r.Stack()))
+	copy(stack, r.Stack())
+	regs := make([]Value, len(r.Regs()))
+	copy(regs, r.Regs())
+
+	replayed := New()
+	_ = replayed.Evaluate("42 rs2")
+
+	err := replayed.Replay(r.Log())
+	if err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+
+	for i := range stack {
+		if stack[i].String() != replayed.Stack()[i].String() {
+			t.Errorf("Expected stack %v, but got %v", stack, replayed.Stack())
+			break
+		}
+	}
+
+	for i := range regs {
+		if regs[i].String() != replayed.Regs()[i].String() {
+			t.Errorf("Expected registers %v, but got %v", regs, replayed.Regs())
+			break
+		}
+	}
+
+	if Script(replayed.Log()) != Script(r.Log()) {
+		t.Errorf("Expected replayed log %v, but got %v", r.Log(), replayed.Log())
+	}
+}
+
+func TestReplayClearsVariables(t *testing.T) {
+
+	r := New()
+	_ = r.Evaluate(": rate 5 ;")
+
+	replayed := New()
+	_ = replayed.Evaluate("42 =rate =old")
+
+	if err := replayed.Replay(r.Log()); err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+	if len(replayed.Vars()) != 0 {
+		t.Errorf("Expected no variables, but got %v", replayed.Vars())
+	}
+}
+
+func TestReplayClearsTVM(t *testing.T) {
+
+	// the log never stores pmt, so the replay must solve with zero
+	r := New()
+	_ = r.Evaluate("10 rsn 5 rsi -1000 rspv fv")
+
+	replayed := New()
+	_ = replayed.Evaluate("100 rspmt")
+
+	if err := replayed.Replay(r.Log()); err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+	if replayed.TVM() != r.TVM() {
+		t.Errorf("Expected registers %v, but got %v", r.TVM(), replayed.TVM())
+	}
+}
+
+func TestReplayModes(t *testing.T) {
+
+	r := New()
+	for _, l := range []string{"deg stack0 complexmode", "1 2 3 30 sin", "-4 sqrt", "hex ws16 unsigned"} {
+		if err := r.Evaluate(l); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+	}
+
+	replayed := New()
+	_ = replayed.Evaluate("grad stack6 fracmode polarmode strict big128 zone UTC")
+
+	if err := replayed.Replay(r.Log()); err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+	if replayed.Settings() != r.Settings() {
+		t.Errorf("Expected settings %+v, but got %+v", r.Settings(), replayed.Settings())
+	}
+	if fmt.Sprint(replayed.Stack()) != fmt.Sprint(r.Stack()) {
+		t.Errorf("Expected stack %v, but got %v", r.Stack(), replayed.Stack())
+	}
+}
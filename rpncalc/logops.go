@@ -0,0 +1,169 @@
+// Package rpncalc logarithmic, exponential and hyperbolic operators
+package rpncalc
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// checkResult maps infinite results to overflow and NaN results to errNaN
+func checkResult(z float64) (float64, error) {
+	if math.IsInf(z, 0) {
+		return 0.0, errOverflow
+	}
+	if math.IsNaN(z) {
+		return 0.0, errNaN
+	}
+	return z, nil
+}
+
+func opLn(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, negative) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			if x == 0 {
+				return 0, errDomain
+			}
+			return cmplx.Log(x), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x <= 0 {
+			return 0.0, errDomain
+		}
+		return math.Log(x), nil
+	})
+}
+
+func opLog10(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, negative) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			if x == 0 {
+				return 0, errDomain
+			}
+			return cmplx.Log10(x), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x <= 0 {
+			return 0.0, errDomain
+		}
+		return math.Log10(x), nil
+	})
+}
+
+func opLog2(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, negative) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			if x == 0 {
+				return 0, errDomain
+			}
+			return cmplx.Log(x) / math.Ln2, nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x <= 0 {
+			return 0.0, errDomain
+		}
+		return math.Log2(x), nil
+	})
+}
+
+func opLogBase(r *RpnCalc, _ string) error {
+	return r.binaryOp(func(x, b float64) (float64, error) {
+		if x <= 0 || b <= 0 || b == 1 {
+			return 0.0, errDomain
+		}
+		return math.Log(x) / math.Log(b), nil
+	})
+}
+
+func opExp(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(complexFunc(cmplx.Exp))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return checkResult(math.Exp(x))
+	})
+}
+
+func opExp10(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return cmplx.Pow(10, x), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return checkResult(math.Pow(10, x))
+	})
+}
+
+func opSinh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(complexFunc(cmplx.Sinh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return checkResult(math.Sinh(x))
+	})
+}
+
+func opCosh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(complexFunc(cmplx.Cosh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return checkResult(math.Cosh(x))
+	})
+}
+
+func opTanh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(complexFunc(cmplx.Tanh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return math.Tanh(x), nil
+	})
+}
+
+func opArcSinh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(complexFunc(cmplx.Asinh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return math.Asinh(x), nil
+	})
+}
+
+func opArcCosh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, func(x float64) bool { return x < 1 }) {
+		return r.complexUnaryOp(complexFunc(cmplx.Acosh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x < 1 {
+			return 0.0, errDomain
+		}
+		return math.Acosh(x), nil
+	})
+}
+
+func opArcTanh(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, outsideUnit) {
+		return r.complexUnaryOp(complexFunc(cmplx.Atanh))
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x <= -1 || x >= 1 {
+			return 0.0, errDomain
+		}
+		return math.Atanh(x), nil
+	})
+}
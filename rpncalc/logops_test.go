@@ -0,0 +1,61 @@
+package rpncalc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogarithmicOps(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		err   error
+	}{
+		{"ln e", "e ln", 0.9999999999999832, nil},
+		{"ln 1", "1 ln", 0, nil},
+		{"ln 0 fails", "0 ln", 0, errDomain},
+		{"ln negative fails", "-1 ln", 0, errDomain},
+		{"log10", "1000 log10", 3, nil},
+		{"log alias", "0.01 log", -2, nil},
+		{"log10 0 fails", "0 log10", 0, errDomain},
+		{"log2", "1024 log2", 10, nil},
+		{"log2 negative fails", "-2 log2", 0, errDomain},
+		{"logb", "81 3 logb", 4, nil},
+		{"logb base 1 fails", "81 1 logb", 0, errDomain},
+		{"logb base 0 fails", "81 0 logb", 0, errDomain},
+		{"logb of 0 fails", "0 3 logb", 0, errDomain},
+		{"exp", "1 exp", math.E, nil},
+		{"exp overflow", "1000 exp", 0, errOverflow},
+		{"exp underflow", "-1000 exp", 0, nil},
+		{"10^x", "3 10^x", 1000, nil},
+		{"exp10 overflow", "400 exp10", 0, errOverflow},
+		{"sinh", "1 sinh", math.Sinh(1), nil},
+		{"sinh overflow", "1000 sinh", 0, errOverflow},
+		{"cosh", "0 cosh", 1, nil},
+		{"cosh overflow", "-1000 cosh", 0, errOverflow},
+		{"tanh", "1000 tanh", 1, nil},
+		{"asinh", "1 sinh asinh", 1, nil},
+		{"acosh", "1 acosh", 0, nil},
+		{"acosh below 1 fails", "0.5 acosh", 0, errDomain},
+		{"atanh", "0.5 tanh atanh", 0.5, nil},
+		{"atanh 1 fails", "1 atanh", 0, errDomain},
+		{"precision mode ln", "big128 1 ln", 0, nil},
+		{"precision mode exp overflow", "big128 1000 exp", 0, errOverflow},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if c.err == nil && !almostEqual(r.Val().Float64(), c.exp) {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, r.Val())
+		}
+	}
+}
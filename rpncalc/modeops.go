@@ -0,0 +1,94 @@
+// Package rpncalc mode operations
+package rpncalc
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+func opFloatMode(r *RpnCalc, _ string) error {
+	r.SetPrecision(0)
+	return nil
+}
+
+func dynOpBigMode(r *RpnCalc, t string) error {
+	bits, err := strconv.ParseUint(t[3:], 10, 32)
+	if err != nil || bits < 1 || bits > MaxPrecision {
+		return errValueNotAllowed
+	}
+
+	if err := r.finite(); err != nil {
+		return err
+	}
+
+	r.SetPrecision(uint(bits))
+	return nil
+}
+
+func opStrictMode(r *RpnCalc, _ string) error {
+	r.SetStrict(true)
+	return nil
+}
+
+func opRelaxedMode(r *RpnCalc, _ string) error {
+	r.SetStrict(false)
+	return nil
+}
+
+// dynOpStackSize sets the stack size (stackX), stack0 gives a dynamic stack
+func dynOpStackSize(r *RpnCalc, t string) error {
+	size, err := strconv.Atoi(t[5:])
+	if err != nil || size < 0 {
+		return errValueNotAllowed
+	}
+
+	return r.SetStackSize(size)
+}
+
+// resetModes sets all modes to their defaults, like in a new RpnCalc.
+// Preferences that do not change results, like the undo depth and the step
+// limit, are kept.
+func (r *RpnCalc) resetModes() {
+	r.SetPrecision(0)
+	_ = r.SetStackSize(newStackSize)
+	r.strict = false
+	r.angle = Radians
+	r.base = Decimal
+	r.wordSize = newWordSize
+	r.signed = true
+	r.complexResults = false
+	r.polar = false
+	r.fraction = false
+	r.zone = time.Local
+}
+
+// finite checks that the values on the stack, in the registers and in the
+// variables can be converted to precision mode, which has no infinities
+// and NaN
+func (r *RpnCalc) finite() error {
+	vs := append(append([]Value{}, r.stack...), r.regs...)
+	for _, v := range r.vars {
+		vs = append(vs, v)
+	}
+
+	for _, v := range vs {
+		if v.IsComplex() || v.IsRat() || v.IsTime() {
+			continue
+		}
+		if v.IsBig() {
+			if v.b.IsInf() {
+				return errOverflow
+			}
+			continue
+		}
+		f := v.Float64()
+		if math.IsNaN(f) {
+			return errNaN
+		}
+		if math.IsInf(f, 0) {
+			return errOverflow
+		}
+	}
+	return nil
+}
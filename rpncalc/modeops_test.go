@@ -0,0 +1,134 @@
+package rpncalc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPrecisionMode(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string // expected value with 20 decimals
+		err   error
+	}{
+		{"exact decimal addition", "big128 0.1 0.2 +", "0.30000000000000000000", nil},
+		{"beyond 2^53", "big128 9007199254740993 1 +", "9007199254740994.00000000000000000000", nil},
+		{"integer power", "big256 2 100 **", "1267650600228229401496703205376.00000000000000000000", nil},
+		{"negative integer power", "big64 2 -2 pow", "0.25000000000000000000", nil},
+		{"fractional power falls back to float", "big64 4 0.5 pow", "2.00000000000000000000", nil},
+		{"division", "big128 1 4 /", "0.25000000000000000000", nil},
+		{"division by zero", "big128 1 0 /", "0.00000000000000000000", errDivisionByZero},
+		{"modulus", "big128 123 7 mod", "4.00000000000000000000", nil},
+		{"square root", "big128 2 sqrt sq", "2.00000000000000000000", nil},
+		{"square root of negative", "big128 4 neg sqrt", "0.00000000000000000000", errNaN},
+		{"inverse", "big128 8 inv", "0.12500000000000000000", nil},
+		{"constant", "big256 pi", "3.14159265358979323846", nil},
+		{"registers", "big128 0.1 rs1 0.2 rr1 +", "0.30000000000000000000", nil},
+		{"back to float", "big128 0.1 0.2 + f64", "0.29999999999999998890", nil},
+		{"values are converted", "0.5 big128", "0.50000000000000000000", nil},
+		{"invalid precision", "big0", "0.00000000000000000000", errValueNotAllowed},
+		{"too large precision", "big9999999", "0.00000000000000000000", errValueNotAllowed},
+		{"infinity", "big64 inf inf -", "0.00000000000000000000", errOverflow},
+		{"infinity times zero", "big64 inf 0 *", "0.00000000000000000000", errOverflow},
+		{"not a number", "big64 nan", "0.00000000000000000000", errNaN},
+		{"infinity to precision mode", "inf big64", "0.00000000000000000000", errOverflow},
+		{"not a number to precision mode", "nan big64", "0.00000000000000000000", errNaN},
+		{"infinity in register", "inf rs1 drop big64", "0.00000000000000000000", errOverflow},
+		{"infinity modulus", "inf big64 2 %", "0.00000000000000000000", errOverflow},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		got := r.Val().Text(20)
+		if got != c.exp {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestBigBinaryOpNaN(t *testing.T) {
+	r := New()
+	r.SetPrecision(64)
+	r.Push(NewBig(new(big.Float).SetInf(false)))
+	r.Push(NewBig(new(big.Float).SetInf(false)))
+
+	if err := opSubtraction(r, "-"); err != errDomain {
+		t.Errorf("Expected error %v, but got %v", errDomain, err)
+	}
+
+	r.Push(NewFloat(2))
+	if err := opModulus(r, "%"); err != errOverflow {
+		t.Errorf("Expected error %v, but got %v", errOverflow, err)
+	}
+}
+
+func TestSetPrecision(t *testing.T) {
+	r := New()
+
+	r.SetPrecision(100)
+	if r.Settings().Precision != 100 {
+		t.Errorf("Expected precision 100, but got %v", r.Settings().Precision)
+	}
+	for i, v := range r.Stack() {
+		if !v.IsBig() {
+			t.Errorf("Expected stack value %d to be big", i)
+		}
+	}
+
+	r.SetPrecision(0)
+	for i, v := range r.Regs() {
+		if v.IsBig() {
+			t.Errorf("Expected register value %d to be float", i)
+		}
+	}
+}
+
+func TestModeTokens(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		check func(Settings) bool
+		err   error
+	}{
+		{"complex mode", "complexmode", func(s Settings) bool { return s.Complex }, nil},
+		{"real mode", "complexmode realmode", func(s Settings) bool { return !s.Complex }, nil},
+		{"polar mode", "polarmode", func(s Settings) bool { return s.Polar }, nil},
+		{"rectangular mode", "polarmode rectmode", func(s Settings) bool { return !s.Polar }, nil},
+		{"strict", "strict", func(s Settings) bool { return s.Strict }, nil},
+		{"relaxed", "strict relaxed", func(s Settings) bool { return !s.Strict }, nil},
+		{"dynamic stack", "stack0", func(s Settings) bool { return s.StackSize == 0 }, nil},
+		{"fixed stack", "stack8", func(s Settings) bool { return s.StackSize == 8 }, nil},
+		{"stack too small", "stack1", nil, errValueNotAllowed},
+		{"stack negative", "stack-1", nil, errValueNotAllowed},
+		{"zone", "zone Asia/Tokyo", func(s Settings) bool { return s.Zone == "Asia/Tokyo" }, nil},
+		{"unknown zone", "zone Nowhere/Atall", nil, errUnknownZone},
+		{"missing zone", "zone", nil, errUnknownZone},
+		{"failed input keeps stack model", "stack0 foo", func(s Settings) bool { return s.StackSize == 4 }, errUnknownInput},
+		{"failed input keeps modes", "strict complexmode zone UTC foo", func(s Settings) bool {
+			return !s.Strict && !s.Complex && s.Zone == "Local"
+		}, errUnknownInput},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.check != nil && !c.check(r.Settings()) {
+			t.Errorf("%q: Unexpected settings %+v", c.name, r.Settings())
+		}
+	}
+}
@@ -1,6 +1,11 @@
 // Package rpncalc operators. Operators modifies the stack or the registers.
 package rpncalc
 
+import (
+	"fmt"
+	"strings"
+)
+
 // OperatorType defines an operator to be static (exact match) or dynamic (postfixed with a value)
 type OperatorType int
 
@@ -9,6 +14,8 @@ const (
 	StaticOp = iota
 	// DynamicOp defines the dynamic operation type
 	DynamicOp
+	// WordOp defines a user defined word
+	WordOp
 )
 
 // OpInfo contains displayable operation information
@@ -17,6 +24,7 @@ type OpInfo struct {
 	Names       []string
 	Prefix      string
 	Description string
+	Arity       int
 }
 
 // Operator defines data needed for one operator
@@ -26,38 +34,315 @@ type Operator struct {
 	Prefix      string   // used by dynamic ops
 	Handler     func(*RpnCalc, string) error
 	Description string
+	Arity       int // number of values used from the stack
 }
 
 // OpsInfo returns an OpInfo slice with all supported static operators
 func OpsInfo() []OpInfo {
 	ois := []OpInfo{}
 	for _, o := range operators {
-		ois = append(ois, OpInfo{o.Type, o.Names, o.Prefix, o.Description})
+		ois = append(ois, OpInfo{o.Type, o.Names, o.Prefix, o.Description, o.Arity})
+	}
+	return ois
+}
+
+// OpsInfo returns an OpInfo slice with all supported static operators
+// followed by the user defined words
+func (r *RpnCalc) OpsInfo() []OpInfo {
+	ois := OpsInfo()
+	for _, w := range r.Words() {
+		ois = append(ois, OpInfo{WordOp, []string{w.Name}, "", "User word: " + strings.Join(w.Body, " "), 0})
 	}
 	return ois
 }
 
+// RegisterOperator adds an operator to the operators supported by all
+// RpnCalc instances. The operator is validated and its names, or prefix,
+// must not collide with numbers, constants, units, keywords or existing
+// operators. Operators should be registered before they are used, e.g. in
+// an init function, since the operators are shared between instances.
+func RegisterOperator(op Operator) error {
+	if op.Handler == nil {
+		return fmt.Errorf("%w: missing handler", errInvalidOperator)
+	}
+	if strings.TrimSpace(op.Description) == "" {
+		return fmt.Errorf("%w: missing description", errInvalidOperator)
+	}
+	if op.Arity < 0 {
+		return fmt.Errorf("%w: negative arity", errInvalidOperator)
+	}
+
+	switch op.Type {
+	case StaticOp:
+		if len(op.Names) < 1 || op.Prefix != "" {
+			return fmt.Errorf("%w: static operators must have names and no prefix", errInvalidOperator)
+		}
+		for _, n := range op.Names {
+			if err := checkOpName(n); err != nil {
+				return err
+			}
+		}
+	case DynamicOp:
+		if len(op.Names) > 0 || op.Prefix == "" {
+			return fmt.Errorf("%w: dynamic operators must have a prefix and no names", errInvalidOperator)
+		}
+		if err := checkOpPrefix(op.Prefix); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %v", errInvalidOperator, op.Type)
+	}
+
+	op.Names = append([]string{}, op.Names...)
+	operators = append(operators, op)
+	return nil
+}
+
+func checkOpName(n string) error {
+	if strings.TrimSpace(n) == "" || strings.ContainsAny(n, " \t") {
+		return fmt.Errorf("%w: %q", errInvalidName, n)
+	}
+	if New().isNumber(n) || isKeyword(n) {
+		return fmt.Errorf("%w: %q", errInvalidName, n)
+	}
+	if isConstant(n) {
+		return fmt.Errorf("%w: %q is a constant", errNameCollision, n)
+	}
+	if isUnit(n) {
+		return fmt.Errorf("%w: %q is a unit", errNameCollision, n)
+	}
+	if findOp(n) != nil {
+		return fmt.Errorf("%w: %q is an operator", errNameCollision, n)
+	}
+	return nil
+}
+
+func checkOpPrefix(p string) error {
+	if strings.ContainsAny(p, " \t") || isKeyword(p) {
+		return fmt.Errorf("%w: %q", errInvalidName, p)
+	}
+	for _, op := range operators {
+		if op.Prefix != "" && (strings.HasPrefix(p, op.Prefix) || strings.HasPrefix(op.Prefix, p)) {
+			return fmt.Errorf("%w: prefix %q overlaps prefix %q", errNameCollision, p, op.Prefix)
+		}
+		for _, n := range op.Names {
+			if strings.HasPrefix(n, p) {
+				return fmt.Errorf("%w: prefix %q matches operator %q", errNameCollision, p, n)
+			}
+		}
+	}
+	for _, c := range constants {
+		for _, n := range c.Names {
+			if strings.HasPrefix(n, p) {
+				return fmt.Errorf("%w: prefix %q matches constant %q", errNameCollision, p, n)
+			}
+		}
+	}
+	for _, u := range units {
+		for _, n := range u.Names {
+			if strings.HasPrefix(n, p) {
+				return fmt.Errorf("%w: prefix %q matches unit %q", errNameCollision, p, n)
+			}
+		}
+	}
+	return nil
+}
+
 var operators = []Operator{
 	// Unary
-	{StaticOp, []string{"neg"}, "", opNegate, "Negates (-x) first value on stack"},
-	{StaticOp, []string{"inv"}, "", opInverse, "Inverts (1/x) first value on stack"},
-	{StaticOp, []string{"sq", "square"}, "", opSquare, "Squares (x^2) first value on stack"},
-	{StaticOp, []string{"sqrt", "root"}, "", opSquareRoot, "Calulates the square root"},
-	{StaticOp, []string{"bin", "b"}, "", opDecToBin, "Converts decimal to binary"},
-	{StaticOp, []string{"dec", "d"}, "", opBinToDec, "Converts binary to decimal"},
+	{StaticOp, []string{"neg"}, "", opNegate, "Negates (-x) first value on stack", 1},
+	{StaticOp, []string{"inv"}, "", opInverse, "Inverts (1/x) first value on stack", 1},
+	{StaticOp, []string{"sq", "square"}, "", opSquare, "Squares (x^2) first value on stack", 1},
+	{StaticOp, []string{"sqrt", "root"}, "", opSquareRoot, "Calulates the square root", 1},
+	// Trigonometry
+	{StaticOp, []string{"sin"}, "", opSin, "Sine of x in the current angle mode", 1},
+	{StaticOp, []string{"cos"}, "", opCos, "Cosine of x in the current angle mode", 1},
+	{StaticOp, []string{"tan"}, "", opTan, "Tangent of x in the current angle mode", 1},
+	{StaticOp, []string{"asin"}, "", opArcSin, "Inverse sine of x in the current angle mode", 1},
+	{StaticOp, []string{"acos"}, "", opArcCos, "Inverse cosine of x in the current angle mode", 1},
+	{StaticOp, []string{"atan"}, "", opArcTan, "Inverse tangent of x in the current angle mode", 1},
+	{StaticOp, []string{"atan2"}, "", opArcTan2, "Angle of the point (x, y) in the current angle mode", 2},
+	{StaticOp, []string{"deg>rad"}, "", opDegToRad, "Converts x from degrees to radians", 1},
+	{StaticOp, []string{"rad>deg"}, "", opRadToDeg, "Converts x from radians to degrees", 1},
+	// Logarithmic, exponential and hyperbolic
+	{StaticOp, []string{"ln"}, "", opLn, "Natural logarithm of x", 1},
+	{StaticOp, []string{"log10", "log"}, "", opLog10, "Base 10 logarithm of x", 1},
+	{StaticOp, []string{"log2"}, "", opLog2, "Base 2 logarithm of x", 1},
+	{StaticOp, []string{"logb"}, "", opLogBase, "Logarithm of y with base x", 2},
+	{StaticOp, []string{"exp"}, "", opExp, "Calculates e to the power of x", 1},
+	{StaticOp, []string{"10^x", "exp10"}, "", opExp10, "Calculates 10 to the power of x", 1},
+	{StaticOp, []string{"sinh"}, "", opSinh, "Hyperbolic sine of x", 1},
+	{StaticOp, []string{"cosh"}, "", opCosh, "Hyperbolic cosine of x", 1},
+	{StaticOp, []string{"tanh"}, "", opTanh, "Hyperbolic tangent of x", 1},
+	{StaticOp, []string{"asinh"}, "", opArcSinh, "Inverse hyperbolic sine of x", 1},
+	{StaticOp, []string{"acosh"}, "", opArcCosh, "Inverse hyperbolic cosine of x", 1},
+	{StaticOp, []string{"atanh"}, "", opArcTanh, "Inverse hyperbolic tangent of x", 1},
+	// Complex
+	{StaticOp, []string{"rect"}, "", opRect, "Creates the complex value y+xi", 2},
+	{StaticOp, []string{"polar"}, "", opPolar, "Creates the complex value with magnitude y and angle x", 2},
+	{StaticOp, []string{"re"}, "", opRealPart, "Real part of x", 1},
+	{StaticOp, []string{"im"}, "", opImaginaryPart, "Imaginary part of x", 1},
+	{StaticOp, []string{"abs"}, "", opAbs, "Absolute value, or magnitude, of x", 1},
+	{StaticOp, []string{"arg"}, "", opArg, "Angle of x in the current angle mode", 1},
+	{StaticOp, []string{"conj"}, "", opConjugate, "Complex conjugate of x", 1},
+	// Fractions
+	{StaticOp, []string{"frac"}, "", opToFraction, "Converts x to an exact fraction", 1},
+	{StaticOp, []string{"approx"}, "", opToDecimal, "Converts the fraction x to a decimal value", 1},
+	{StaticOp, []string{"limden"}, "", opLimitDenominator, "Closest fraction to y with a denominator of at most x", 2},
+	// Units
+	{StaticOp, []string{"nounit"}, "", opStripUnit, "Removes the unit of x, keeping its value", 1},
+	{StaticOp, []string{"si"}, "", opBaseUnit, "Converts x to the base units m, kg, s, K and B", 1},
+	// Dates and durations
+	{StaticOp, []string{"now"}, "", opNow, "Puts the current date and time on the stack", 0},
+	{StaticOp, []string{"today"}, "", opToday, "Puts the current date at midnight on the stack", 0},
+	{StaticOp, []string{"date"}, "", opUnixToDate, "Converts the Unix timestamp x in seconds to a date", 1},
+	{StaticOp, []string{"unix"}, "", opDateToUnix, "Converts the date x to a Unix timestamp in seconds", 1},
+	{StaticOp, []string{"diff"}, "", opTimeDifference, "Duration between the dates y and x", 2},
+	{StaticOp, []string{"days"}, "", opToDays, "Converts the duration x to days", 1},
+	{StaticOp, []string{"hours"}, "", opToHours, "Converts the duration x to hours", 1},
+	{StaticOp, []string{"minutes"}, "", opToMinutes, "Converts the duration x to minutes", 1},
+	{StaticOp, []string{"seconds"}, "", opToSeconds, "Converts the duration x to seconds", 1},
 	// Binary
-	{StaticOp, []string{"+", "add"}, "", opAddition, "Adds (x+y) first two values on stack"},
-	{StaticOp, []string{"-", "sub"}, "", opSubtraction, "Subtracts (y-x) first two values on stack"},
-	{StaticOp, []string{"*", "mul"}, "", opMultiplication, "Multiplies (y*x) first two values on stack"},
-	{StaticOp, []string{"/", "div"}, "", opDivision, "Divides (y/x) first two values on stack"},
-	{StaticOp, []string{"**", "pow"}, "", opPower, "Calculates y to the power of x (y**x)"},
-	{StaticOp, []string{"%", "mod"}, "", opModulus, "Calculates x modulus y"},
+	{StaticOp, []string{"+", "add"}, "", opAddition, "Adds (x+y) first two values on stack", 2},
+	{StaticOp, []string{"-", "sub"}, "", opSubtraction, "Subtracts (y-x) first two values on stack", 2},
+	{StaticOp, []string{"*", "mul"}, "", opMultiplication, "Multiplies (y*x) first two values on stack", 2},
+	{StaticOp, []string{"/", "div"}, "", opDivision, "Divides (y/x) first two values on stack", 2},
+	{StaticOp, []string{"**", "pow"}, "", opPower, "Calculates y to the power of x (y**x)", 2},
+	{StaticOp, []string{"%", "mod"}, "", opModulus, "Calculates x modulus y", 2},
+	// Integer and number theory, operands must be integers
+	{StaticOp, []string{"gcd"}, "", opGCD, "Greatest common divisor of y and x", 2},
+	{StaticOp, []string{"lcm"}, "", opLCM, "Least common multiple of y and x", 2},
+	{StaticOp, []string{"fact"}, "", opFactorial, "Factorial of x, the gamma function of x+1 for non integers", 1},
+	{StaticOp, []string{"ncr"}, "", opCombinations, "Number of combinations of x items out of y", 2},
+	{StaticOp, []string{"npr"}, "", opPermutations, "Number of permutations of x items out of y", 2},
+	{StaticOp, []string{"isprime"}, "", opIsPrime, "Checks if x is a prime", 1},
+	{StaticOp, []string{"nextprime"}, "", opNextPrime, "Smallest prime larger than x", 1},
+	{StaticOp, []string{"factor"}, "", opFactorize, "Replaces x with its prime factors, the largest in pos 0", 1},
+	{StaticOp, []string{"idiv"}, "", opIntegerDivision, "Integer division of y by x, truncated towards zero", 2},
+	{StaticOp, []string{"divmod"}, "", opDivMod, "Replaces y and x with the quotient and remainder of y/x", 2},
+	{StaticOp, []string{"powmod"}, "", opPowMod, "Calculates z to the power of y modulo x", 3},
+	// Comparison and boolean, true is 1 and false is 0
+	{StaticOp, []string{"<", "lt"}, "", opLess, "Checks if y is less than x", 2},
+	{StaticOp, []string{"<=", "le"}, "", opLessOrEqual, "Checks if y is less than or equal to x", 2},
+	{StaticOp, []string{">", "gt"}, "", opGreater, "Checks if y is greater than x", 2},
+	{StaticOp, []string{">=", "ge"}, "", opGreaterOrEqual, "Checks if y is greater than or equal to x", 2},
+	{StaticOp, []string{"==", "eq"}, "", opEqual, "Checks if y is equal to x", 2},
+	{StaticOp, []string{"!=", "ne"}, "", opNotEqual, "Checks if y is not equal to x", 2},
+	{StaticOp, []string{"&&"}, "", opAnd, "Logical and, true if both y and x are non zero", 2},
+	{StaticOp, []string{"||"}, "", opOr, "Logical or, true if y or x is non zero", 2},
+	{StaticOp, []string{"!"}, "", opNot, "Logical not, true if x is zero", 1},
+	// Bitwise
+	{StaticOp, []string{"and"}, "", opBitAnd, "Bitwise and of y and x", 2},
+	{StaticOp, []string{"or"}, "", opBitOr, "Bitwise or of y and x", 2},
+	{StaticOp, []string{"xor"}, "", opBitXor, "Bitwise exclusive or of y and x", 2},
+	{StaticOp, []string{"not"}, "", opBitNot, "Bitwise complement of x", 1},
+	{StaticOp, []string{"shl"}, "", opShiftLeft, "Shifts y left x bits", 2},
+	{StaticOp, []string{"shr"}, "", opShiftRight, "Shifts y right x bits, filling with zeros", 2},
+	{StaticOp, []string{"sar", "ashr"}, "", opArithmeticShiftRight, "Shifts y right x bits, filling with the sign bit", 2},
+	{StaticOp, []string{"rol"}, "", opRotateLeft, "Rotates y left x bits", 2},
+	{StaticOp, []string{"ror"}, "", opRotateRight, "Rotates y right x bits", 2},
+	{StaticOp, []string{"bset"}, "", opBitSet, "Sets bit x of y", 2},
+	{StaticOp, []string{"bclr"}, "", opBitClear, "Clears bit x of y", 2},
+	{StaticOp, []string{"btest"}, "", opBitTest, "Pushes 1 if bit x of y is set, otherwise 0", 2},
+	{StaticOp, []string{"popcnt"}, "", opPopCount, "Number of set bits in x", 1},
+	{StaticOp, []string{"clz"}, "", opLeadingZeros, "Number of leading zero bits in x", 1},
 	// Stack
-	{StaticOp, []string{"sw", "swap"}, "", opSwap, "Swap pos 0 and pos 1 on the stack"},
+	{StaticOp, []string{"sw", "swap"}, "", opSwap, "Swap pos 0 and pos 1 on the stack", 2},
+	{StaticOp, []string{"dup"}, "", opDup, "Duplicates the value in pos 0", 1},
+	{StaticOp, []string{"drop"}, "", opDrop, "Removes the value in pos 0", 1},
+	{StaticOp, []string{"over"}, "", opOver, "Pushes a copy of the value in pos 1", 2},
+	{StaticOp, []string{"rot"}, "", opRot, "Rotates the value in pos 2 to pos 0", 3},
+	{StaticOp, []string{"-rot"}, "", opRotBack, "Rotates the value in pos 0 to pos 2", 3},
+	{StaticOp, []string{"nip"}, "", opNip, "Removes the value in pos 1", 2},
+	{StaticOp, []string{"tuck"}, "", opTuck, "Inserts a copy of the value in pos 0 below pos 1", 2},
+	{StaticOp, []string{"roll"}, "", opRoll, "Moves the value in pos x, after removing x, to pos 0", 1},
+	{StaticOp, []string{"pick"}, "", opPick, "Pushes a copy of the value in pos x, after removing x", 1},
+	{StaticOp, []string{"depth"}, "", opDepth, "Pushes the number of values on the stack", 0},
+	{StaticOp, []string{"clear", "clst"}, "", opClearStack, "Clears the stack", 0},
+	{DynamicOp, []string{}, "roll", dynOpRoll, "Moves (rollX) the value in pos X to pos 0", 0},
+	{DynamicOp, []string{}, "pick", dynOpPick, "Pushes (pickX) a copy of the value in pos X", 0},
+	// Aggregates, on all entered values or, like sum5, on the first X values
+	{StaticOp, []string{"sum"}, "", opSum, "Sum of all values on the stack", 0},
+	{StaticOp, []string{"prod"}, "", opProduct, "Product of all values on the stack", 0},
+	{StaticOp, []string{"mean"}, "", opMean, "Mean of all values on the stack", 0},
+	{StaticOp, []string{"median"}, "", opMedian, "Median of all values on the stack", 0},
+	{StaticOp, []string{"min"}, "", opMin, "Smallest of all values on the stack", 0},
+	{StaticOp, []string{"max"}, "", opMax, "Largest of all values on the stack", 0},
+	{StaticOp, []string{"sort"}, "", opSort, "Sorts all values on the stack, the largest in pos 0", 0},
+	{StaticOp, []string{"reverse"}, "", opReverse, "Reverses the order of all values on the stack", 0},
+	{DynamicOp, []string{}, "sum", opSum, "Sum (sumX) of the first X values", 0},
+	{DynamicOp, []string{}, "prod", opProduct, "Product (prodX) of the first X values", 0},
+	{DynamicOp, []string{}, "mean", opMean, "Mean (meanX) of the first X values", 0},
+	{DynamicOp, []string{}, "median", opMedian, "Median (medianX) of the first X values", 0},
+	{DynamicOp, []string{}, "min", opMin, "Smallest (minX) of the first X values", 0},
+	{DynamicOp, []string{}, "max", opMax, "Largest (maxX) of the first X values", 0},
+	{DynamicOp, []string{}, "sort", opSort, "Sorts (sortX) the first X values, the largest in pos 0", 0},
+	{DynamicOp, []string{}, "reverse", opReverse, "Reverses (reverseX) the order of the first X values", 0},
+	// Statistics
+	{StaticOp, []string{"s+", "Σ+"}, "", opStatAdd, "Adds x, and y, to the statistics, x is replaced by n", 1},
+	{StaticOp, []string{"s-", "Σ-"}, "", opStatRemove, "Removes x, and y, from the statistics, x is replaced by n", 1},
+	{StaticOp, []string{"sclear"}, "", opStatClear, "Clears the statistics", 0},
+	{StaticOp, []string{"sn"}, "", opStatN, "Pushes the number of accumulated values", 0},
+	{StaticOp, []string{"ssum"}, "", opStatSum, "Pushes the sum of y and the sum of x", 0},
+	{StaticOp, []string{"smean"}, "", opStatMean, "Pushes the mean of y and the mean of x", 0},
+	{StaticOp, []string{"sdev", "ssdev"}, "", opStatSampleDev, "Pushes the sample standard deviation of y and of x", 0},
+	{StaticOp, []string{"psdev", "spsdev"}, "", opStatPopulationDev, "Pushes the population standard deviation of y and of x", 0},
+	{StaticOp, []string{"slope"}, "", opStatSlope, "Pushes the slope of the linear regression line", 0},
+	{StaticOp, []string{"intercept"}, "", opStatIntercept, "Pushes the y intercept of the linear regression line", 0},
+	{StaticOp, []string{"corr"}, "", opStatCorrelation, "Pushes the correlation coefficient of x and y", 0},
+	{StaticOp, []string{"yhat"}, "", opStatEstimateY, "Estimates y for x using the linear regression line", 1},
+	{StaticOp, []string{"xhat"}, "", opStatEstimateX, "Estimates x for y using the linear regression line", 1},
+	// Financial, the interest rate is in percent per period
+	{StaticOp, []string{"nper"}, "", opTVMN, "Solves the number of periods, store with rsn", 0},
+	{StaticOp, []string{"irate"}, "", opTVMI, "Solves the interest rate per period, store with rsi", 0},
+	{StaticOp, []string{"pv"}, "", opTVMPV, "Solves the present value, store with rspv", 0},
+	{StaticOp, []string{"pmt"}, "", opTVMPMT, "Solves the payment per period, store with rspmt", 0},
+	{StaticOp, []string{"fv"}, "", opTVMFV, "Solves the future value, store with rsfv", 0},
+	{StaticOp, []string{"tvmclear"}, "", opTVMClear, "Clears the time value of money registers", 0},
+	{StaticOp, []string{"pbeg"}, "", opPaymentBegin, "Payments at the beginning of the periods", 0},
+	{StaticOp, []string{"pend"}, "", opPaymentEnd, "Payments at the end of the periods", 0},
+	{StaticOp, []string{"amort"}, "", opAmortize, "Amortizes periods y to x, pushing interest, principal and balance", 2},
+	{StaticOp, []string{"npv"}, "", opNPV, "Net present value at the rate x of y cash flows, period 0 deepest", 2},
+	{StaticOp, []string{"irr"}, "", opIRR, "Internal rate of return of x cash flows, period 0 deepest", 1},
+	{StaticOp, []string{"rnpv"}, "", opRegNPV, "Net present value at the rate x of the cash flows in registers 0 to y-1", 2},
+	{StaticOp, []string{"rirr"}, "", opRegIRR, "Internal rate of return of the cash flows in registers 0 to x-1", 1},
+	{StaticOp, []string{"eff"}, "", opEffectiveRate, "Effective annual rate of the nominal rate y with x periods per year", 2},
+	{StaticOp, []string{"nom"}, "", opNominalRate, "Nominal annual rate of the effective rate y with x periods per year", 2},
 	// Register
-	{DynamicOp, []string{}, "rs", dynOpRegStore, "Store (rsX) value in register X"},
-	{DynamicOp, []string{}, "rr", dynOpRegRestore, "Restore (rrX) value from register X"},
-	{DynamicOp, []string{}, "rc", dynOpRegClear, "Clear (rcX) value from register X"},
+	{StaticOp, []string{"lastx"}, "", opLastX, "Pushes x as it was before the last operator", 0},
+	{StaticOp, []string{"lasty"}, "", opLastY, "Pushes y as it was before the last binary operator", 0},
+	{DynamicOp, []string{}, "rs", dynOpRegStore, "Store (rsX) value in register X, or in n, i, pv, pmt or fv", 1},
+	{DynamicOp, []string{}, "rr", dynOpRegRestore, "Restore (rrX) value from register X, or from n, i, pv, pmt or fv", 0},
+	{DynamicOp, []string{}, "rc", dynOpRegClear, "Clear (rcX) value from register X, or n, i, pv, pmt or fv", 0},
+	{DynamicOp, []string{}, "rx", dynOpRegExchange, "Exchange (rxX) x with register X", 1},
+	// Variables
+	{DynamicOp, []string{}, "=", dynOpVarStore, "Store (=name) value in the variable name", 1},
+	{DynamicOp, []string{}, "$", dynOpVarRecall, "Recall ($name) value from the variable name, or use just name", 0},
+	// Mode
+	{StaticOp, []string{"f64", "float"}, "", opFloatMode, "Switch to float64 mode", 0},
+	{DynamicOp, []string{}, "big", dynOpBigMode, "Switch to precision mode (bigX) with X bits mantissa, e.g. big256", 0},
+	{StaticOp, []string{"deg"}, "", opDegreesMode, "Switch to degrees angle mode", 0},
+	{StaticOp, []string{"rad"}, "", opRadiansMode, "Switch to radians angle mode", 0},
+	{StaticOp, []string{"grad"}, "", opGradiansMode, "Switch to gradians angle mode", 0},
+	{StaticOp, []string{"dec", "d"}, "", opDecimalBase, "Display values in decimal", 0},
+	{StaticOp, []string{"hex"}, "", opHexadecimalBase, "Display integer values in hexadecimal", 0},
+	{StaticOp, []string{"oct"}, "", opOctalBase, "Display integer values in octal", 0},
+	{StaticOp, []string{"bin", "b"}, "", opBinaryBase, "Display integer values in binary", 0},
+	{DynamicOp, []string{}, "ws", dynOpWordSize, "Set integer word size (wsX) to X bits, 8, 16, 32 or 64", 0},
+	{StaticOp, []string{"signed"}, "", opSigned, "Interpret integers as two's complement signed", 0},
+	{StaticOp, []string{"unsigned"}, "", opUnsigned, "Interpret integers as unsigned", 0},
+	{StaticOp, []string{"fracmode"}, "", opFractionMode, "Switch to fraction mode, decimals are entered as fractions", 0},
+	{StaticOp, []string{"decmode"}, "", opDecimalMode, "Switch off fraction mode", 0},
+	{StaticOp, []string{"complexmode"}, "", opComplexMode, "Switch to complex results outside the real domain, like -4 sqrt", 0},
+	{StaticOp, []string{"realmode"}, "", opRealMode, "Switch off complex results", 0},
+	{StaticOp, []string{"polarmode"}, "", opPolarMode, "Display complex values in polar form", 0},
+	{StaticOp, []string{"rectmode"}, "", opRectangularMode, "Display complex values in rectangular form", 0},
+	{StaticOp, []string{"strict"}, "", opStrictMode, "Fail when there are fewer values on the stack than an operator uses", 0},
+	{StaticOp, []string{"relaxed"}, "", opRelaxedMode, "Use zeros when there are fewer values on the stack than an operator uses", 0},
+	{DynamicOp, []string{}, "stack", dynOpStackSize, "Set the stack size (stackX) to X values, stack0 gives a dynamic stack", 0},
+	// History
+	{StaticOp, []string{"undo"}, "", opUndo, "Undo the last input line, or token, see setting undotokens", 0},
+	{StaticOp, []string{"redo"}, "", opRedo, "Redo the last undone input line, or token", 0},
 
 	// TODO: Add more operators
 }
@@ -1,6 +1,7 @@
 package rpncalc
 
 import (
+	"errors"
 	"math"
 	"strings"
 	"testing"
@@ -35,6 +36,11 @@ func TestOpsInfo(t *testing.T) {
 			}
 		}
 
+		// Check that the arity is sane
+		if o.Arity < 0 || o.Arity > newStackSize {
+			t.Errorf("Bad arity for operator %v", o)
+		}
+
 		// Check that a description exists
 		if "" == o.Description {
 			t.Errorf("Empty description for operator %v", o)
@@ -43,8 +49,102 @@ func TestOpsInfo(t *testing.T) {
 
 }
 
+func TestRegisterOperator(t *testing.T) {
+
+	saved := operators
+	defer func() { operators = saved }()
+
+	cube := func(r *RpnCalc, _ string) error {
+		return r.UnaryOp(func(x float64) (float64, error) {
+			return x * x * x, nil
+		})
+	}
+	hypot := func(r *RpnCalc, _ string) error {
+		return r.BinaryOp(func(y, x float64) (float64, error) {
+			return math.Hypot(y, x), nil
+		})
+	}
+	push := func(r *RpnCalc, t string) error {
+		r.Push(NewFloat(float64(len(t))))
+		return nil
+	}
+
+	cases := []struct {
+		name string
+		op   Operator
+		err  error
+	}{
+		{"static", Operator{StaticOp, []string{"cube"}, "", cube, "Cubes x", 1}, nil},
+		{"binary", Operator{StaticOp, []string{"hypot"}, "", hypot, "Hypotenuse", 2}, nil},
+		{"dynamic", Operator{DynamicOp, []string{}, "len", push, "Pushes length of token", 0}, nil},
+		{"missing handler", Operator{StaticOp, []string{"nop"}, "", nil, "Nothing", 0}, errInvalidOperator},
+		{"missing description", Operator{StaticOp, []string{"nop"}, "", cube, "", 0}, errInvalidOperator},
+		{"static without names", Operator{StaticOp, []string{}, "", cube, "Cubes x", 0}, errInvalidOperator},
+		{"static with prefix", Operator{StaticOp, []string{"cube3"}, "cu", cube, "Cubes x", 0}, errInvalidOperator},
+		{"dynamic without prefix", Operator{DynamicOp, []string{}, "", cube, "Cubes x", 0}, errInvalidOperator},
+		{"negative arity", Operator{StaticOp, []string{"nop"}, "", cube, "Nothing", -1}, errInvalidOperator},
+		{"unknown type", Operator{OperatorType(42), []string{"nop"}, "", cube, "Nothing", 0}, errInvalidOperator},
+		{"empty name", Operator{StaticOp, []string{" "}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"number name", Operator{StaticOp, []string{"12"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"keyword name", Operator{StaticOp, []string{"if"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"constant name", Operator{StaticOp, []string{"pi"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"fraction name", Operator{StaticOp, []string{"1/3"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"literal name", Operator{StaticOp, []string{"0xff"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"duration name", Operator{StaticOp, []string{"1h30m"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"date name", Operator{StaticOp, []string{"2024-03-01"}, "", cube, "Cubes x", 0}, errInvalidName},
+		{"unit name", Operator{StaticOp, []string{"m"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"unit alias name", Operator{StaticOp, []string{"ft"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"operator name", Operator{StaticOp, []string{"sqrt"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"registered name", Operator{StaticOp, []string{"cube"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"dynamic operator name", Operator{StaticOp, []string{"rs1"}, "", cube, "Cubes x", 0}, errNameCollision},
+		{"overlapping prefix", Operator{DynamicOp, []string{}, "r", push, "Push", 0}, errNameCollision},
+		{"prefix matching operator", Operator{DynamicOp, []string{}, "sq", push, "Push", 0}, errNameCollision},
+		{"prefix matching unit", Operator{DynamicOp, []string{}, "foo", push, "Push", 0}, errNameCollision},
+		{"prefix matching constant", Operator{DynamicOp, []string{}, "ph", push, "Push", 0}, errNameCollision},
+	}
+
+	for _, c := range cases {
+		err := RegisterOperator(c.op)
+		if !errors.Is(err, c.err) {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+		}
+	}
+
+	r := New()
+	if err := r.Evaluate("2 cube 3 4 hypot lenxyz"); err != nil {
+		t.Fatalf("Failed to use registered operators, got error %v", err)
+	}
+
+	exp := []float64{6, 5, 8, 0}
+	for i := range exp {
+		if r.Stack()[i].Float64() != exp[i] {
+			t.Errorf("Expected stack %v, but got %v", exp, r.Stack())
+			break
+		}
+	}
+
+	found := false
+	for _, o := range r.OpsInfo() {
+		if len(o.Names) > 0 && o.Names[0] == "cube" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected registered operator cube in operators info")
+	}
+}
+
 // Helper func for checking that two float64 are almost equal
 func almostEqual(x, y float64) bool {
 	delta := 0.000000000000001
 	return math.Abs(x-y) < delta
 }
+
+// Helper func for creating a slice of float values
+func values(fs ...float64) []Value {
+	vs := make([]Value, len(fs))
+	for i, f := range fs {
+		vs[i] = NewFloat(f)
+	}
+	return vs
+}
@@ -0,0 +1,258 @@
+// Package rpncalc programmer mode, integer literals and display bases
+package rpncalc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Base defines the number base used when displaying values
+type Base int
+
+const (
+	// Decimal is the default base, values are displayed as real numbers
+	Decimal Base = iota
+	// Hexadecimal displays integer values with a 0x prefix
+	Hexadecimal
+	// Octal displays integer values with a 0o prefix
+	Octal
+	// Binary displays integer values with a 0b prefix
+	Binary
+)
+
+var baseNames = []string{"dec", "hex", "oct", "bin"}
+
+// String returns the short name of the base
+func (b Base) String() string {
+	if b < 0 || int(b) >= len(baseNames) {
+		return fmt.Sprintf("Base(%d)", int(b))
+	}
+	return baseNames[b]
+}
+
+// MarshalText makes the base show up with its name in JSON
+func (b Base) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// ParseBase returns the base with the short name s
+func ParseBase(s string) (Base, error) {
+	for i, n := range baseNames {
+		if n == s {
+			return Base(i), nil
+		}
+	}
+	return Decimal, errValueNotAllowed
+}
+
+// radix returns the number of digits in the base
+func (b Base) radix() int {
+	switch b {
+	case Hexadecimal:
+		return 16
+	case Octal:
+		return 8
+	case Binary:
+		return 2
+	}
+	return 10
+}
+
+// prefix returns the literal prefix of the base
+func (b Base) prefix() string {
+	switch b {
+	case Hexadecimal:
+		return "0x"
+	case Octal:
+		return "0o"
+	case Binary:
+		return "0b"
+	}
+	return ""
+}
+
+// wordSizes are the supported integer word sizes in bits
+var wordSizes = []int{8, 16, 32, 64}
+
+// SetBase sets the base used when formatting values
+func (r *RpnCalc) SetBase(b Base) {
+	r.base = b
+}
+
+// SetWordSize sets the integer word size in bits, 8, 16, 32 or 64
+func (r *RpnCalc) SetWordSize(bits int) error {
+	for _, s := range wordSizes {
+		if s == bits {
+			r.wordSize = bits
+			return nil
+		}
+	}
+	return errValueNotAllowed
+}
+
+// SetSigned sets if integers are interpreted as two's complement signed
+// or as unsigned values
+func (r *RpnCalc) SetSigned(signed bool) {
+	r.signed = signed
+}
+
+// Format formats the value in the current base, followed by its unit.
+// Decimal values are formatted with prec decimals, other bases show the
+// integer part of the value as a bit pattern of the current word size.
+func (r *RpnCalc) Format(v Value, prec int) string {
+	return r.formatNumber(v, prec) + v.unitSuffix()
+}
+
+func (r *RpnCalc) formatNumber(v Value, prec int) string {
+	if v.IsComplex() && r.polar {
+		return r.polarText(v, prec)
+	}
+	if v.IsRat() && r.fraction {
+		return mixedText(v.q)
+	}
+	if r.base == Decimal {
+		return v.numberText(prec)
+	}
+
+	x, ok := r.integer(v)
+	if !ok {
+		return v.numberText(prec)
+	}
+	if x.Sign() < 0 {
+		x.Add(x, new(big.Int).Lsh(big.NewInt(1), uint(r.wordSize)))
+	}
+
+	return r.base.prefix() + x.Text(r.base.radix())
+}
+
+// integer returns the integer part of v wrapped to the word size and
+// interpreted as signed or unsigned, or false if v is not a finite number
+func (r *RpnCalc) integer(v Value) (*big.Int, bool) {
+	if v.IsComplex() || v.IsTime() {
+		return nil, false
+	}
+	if v.IsRat() {
+		return r.wrap(new(big.Int).Quo(v.q.Num(), v.q.Denom())), true
+	}
+	if v.IsBig() {
+		if v.b.IsInf() {
+			return nil, false
+		}
+		x, _ := v.b.Int(nil)
+		return r.wrap(x), true
+	}
+
+	f := v.Float64()
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, false
+	}
+
+	x, _ := big.NewFloat(f).Int(nil)
+	return r.wrap(x), true
+}
+
+// wrap truncates x to the word size, keeping the sign when signed
+func (r *RpnCalc) wrap(x *big.Int) *big.Int {
+	size := new(big.Int).Lsh(big.NewInt(1), uint(r.wordSize))
+	x = new(big.Int).Mod(x, size)
+	if r.signed && x.Bit(r.wordSize-1) == 1 {
+		x.Sub(x, size)
+	}
+	return x
+}
+
+// intValue converts an integer to a value in the current mode
+func (r *RpnCalc) intValue(x *big.Int) Value {
+	if r.prec == 0 {
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return NewFloat(f)
+	}
+	return NewBig(new(big.Float).SetPrec(r.prec).SetInt(x))
+}
+
+// parseLiteral parses 0x, 0o and 0b integer literals, returning false if
+// the token is not such a literal. The literal is a bit pattern of the
+// word size, interpreted as signed or unsigned, and a literal with more
+// bits than the word size is an overflow. A minus sign negates the literal
+// before it is wrapped to the word size. Literals too large for a float64
+// are kept exact.
+func (r *RpnCalc) parseLiteral(t string) (Value, bool, error) {
+	neg := strings.HasPrefix(t, "-")
+	digits := strings.TrimPrefix(t, "-")
+	if len(digits) < 3 || digits[0] != '0' {
+		return Value{}, false, nil
+	}
+
+	var radix int
+	switch digits[1] {
+	case 'x', 'X':
+		radix = 16
+	case 'o', 'O':
+		radix = 8
+	case 'b', 'B':
+		radix = 2
+	default:
+		return Value{}, false, nil
+	}
+
+	u, err := strconv.ParseUint(digits[2:], radix, 64)
+	if err != nil {
+		if e, ok := err.(*strconv.NumError); ok && e.Err == strconv.ErrRange {
+			return Value{}, true, errOverflow
+		}
+		return Value{}, false, nil
+	}
+	if r.wordSize < 64 && u>>uint(r.wordSize) != 0 {
+		return Value{}, true, errOverflow
+	}
+
+	x := new(big.Int).SetUint64(u)
+	if neg {
+		x.Neg(x)
+	}
+	x = r.wrap(x)
+
+	return r.intResult(x), true, nil
+}
+
+func opDecimalBase(r *RpnCalc, _ string) error {
+	r.SetBase(Decimal)
+	return nil
+}
+
+func opHexadecimalBase(r *RpnCalc, _ string) error {
+	r.SetBase(Hexadecimal)
+	return nil
+}
+
+func opOctalBase(r *RpnCalc, _ string) error {
+	r.SetBase(Octal)
+	return nil
+}
+
+func opBinaryBase(r *RpnCalc, _ string) error {
+	r.SetBase(Binary)
+	return nil
+}
+
+func dynOpWordSize(r *RpnCalc, t string) error {
+	bits, err := strconv.Atoi(t[2:])
+	if err != nil {
+		return errValueNotAllowed
+	}
+
+	return r.SetWordSize(bits)
+}
+
+func opSigned(r *RpnCalc, _ string) error {
+	r.SetSigned(true)
+	return nil
+}
+
+func opUnsigned(r *RpnCalc, _ string) error {
+	r.SetSigned(false)
+	return nil
+}
@@ -0,0 +1,97 @@
+package rpncalc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIntegerLiterals(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		err   error
+	}{
+		{"hex", "0xff", 255, nil},
+		{"upper case hex", "0XFF", 255, nil},
+		{"octal", "0o17", 15, nil},
+		{"binary", "0b101", 5, nil},
+		{"negative hex", "-0x10", -16, nil},
+		{"arithmetic", "0x10 0b1 +", 17, nil},
+		{"signed 8 bits", "ws8 0xff", -1, nil},
+		{"unsigned 8 bits", "ws8 unsigned 0xff", 255, nil},
+		{"signed 16 bits", "ws16 0x8000", -32768, nil},
+		{"negative smallest signed 8 bits", "ws8 -0x80", -128, nil},
+		{"negative signed 8 bits wraps", "ws8 -0xff", 1, nil},
+		{"negative unsigned 8 bits", "ws8 unsigned -0x1", 255, nil},
+		{"word size overflow", "ws8 0x1ff", 0, errOverflow},
+		{"64 bit overflow", "0x1ffffffffffffffff", 0, errOverflow},
+		{"invalid digits", "0b102", 0, errUnknownInput},
+		{"invalid word size", "ws12", 0, errValueNotAllowed},
+		{"precision mode keeps all bits", "big128 unsigned 0xffffffffffffffff 0xfffffffffffffffe -", 1, nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if c.err == nil && r.Val().Float64() != c.exp {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, r.Val())
+		}
+	}
+}
+
+func TestFormatBase(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string
+	}{
+		{"decimal", "10.5", "10.50"},
+		{"hex", "255 hex", "0xff"},
+		{"octal", "8 oct", "0o10"},
+		{"binary", "5 bin", "0b101"},
+		{"binary alias", "5 b", "0b101"},
+		{"integer part", "5.9 hex", "0x5"},
+		{"negative is two's complement", "-1 ws8 hex", "0xff"},
+		{"negative 64 bits", "-2 hex", "0xfffffffffffffffe"},
+		{"wraps to word size", "256 ws8 hex", "0x0"},
+		{"back to decimal", "5 bin dec", "5.00"},
+		{"precision mode", "big128 0xffffffffffffffff hex", "0xffffffffffffffff"},
+		{"largest signed 64 bits", "0x7fffffffffffffff hex", "0x7fffffffffffffff"},
+		{"largest signed 64 bits decimal", "0x7fffffffffffffff", "9223372036854775807.00"},
+		{"beyond 2^53", "0x20000000000001 hex", "0x20000000000001"},
+		{"unsigned 64 bits", "unsigned 0xffffffffffffffff", "18446744073709551615.00"},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		if err := r.Evaluate(c.input); err != nil {
+			t.Errorf("%q: Unexpected error %v", c.name, err)
+			continue
+		}
+
+		if s := r.Format(r.Val(), 2); s != c.exp {
+			t.Errorf("%q: Expected %q, but got %q", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestFormatBaseInfinity(t *testing.T) {
+	r := New()
+	r.SetPrecision(64)
+	r.SetBase(Hexadecimal)
+	r.Push(NewBig(new(big.Float).SetInf(false)))
+
+	if s := r.Format(r.Val(), 2); s != "+Inf" {
+		t.Errorf("Expected %q, but got %q", "+Inf", s)
+	}
+}
@@ -0,0 +1,269 @@
+// Package rpncalc exact fraction operations
+package rpncalc
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// SetFraction sets fraction mode. In fraction mode decimal numbers are
+// entered as exact fractions and fractions are formatted as mixed numbers.
+func (r *RpnCalc) SetFraction(on bool) {
+	r.fraction = on
+}
+
+// parseRat parses fraction literals like 1/3, and in fraction mode also
+// decimal numbers, as exact fractions. It returns false if the token is
+// not such a number.
+func (r *RpnCalc) parseRat(t string) (Value, bool, error) {
+	if !strings.Contains(t, "/") && !r.fraction {
+		return Value{}, false, nil
+	}
+	if n := strings.Index(t, "/"); n >= 0 {
+		d, err := strconv.ParseInt(t[n+1:], 10, 64)
+		if err == nil && d == 0 {
+			if _, err := strconv.ParseInt(t[:n], 10, 64); err == nil {
+				return Value{}, true, errDivisionByZero
+			}
+		}
+	}
+
+	q, ok := new(big.Rat).SetString(t)
+	if !ok {
+		return Value{}, false, nil
+	}
+	return NewRat(q), true, nil
+}
+
+// mixedText formats a fraction as a mixed number, like 1 2/3
+func mixedText(q *big.Rat) string {
+	if q.IsInt() {
+		return q.Num().String()
+	}
+
+	whole, rem := new(big.Int).QuoRem(q.Num(), q.Denom(), new(big.Int))
+	if whole.Sign() == 0 {
+		return q.RatString()
+	}
+
+	return whole.String() + " " + rem.Abs(rem).String() + "/" + q.Denom().String()
+}
+
+// exactRat returns v as a fraction if it is a fraction or an integer
+func exactRat(v Value) (*big.Rat, bool) {
+	q := v.Rat()
+	if q == nil || (!v.IsRat() && !q.IsInt()) {
+		return nil, false
+	}
+	return q, true
+}
+
+// ratArgs returns true if one of the first n values on the stack is a
+// fraction and the others are fractions or integers
+func (r *RpnCalc) ratArgs(n int) bool {
+	found := false
+	for i := 0; i < n && i < len(r.stack); i++ {
+		if _, ok := exactRat(r.stack[i]); !ok {
+			return false
+		}
+		found = found || r.stack[i].IsRat()
+	}
+	return found
+}
+
+func (r *RpnCalc) ratUnaryOp(f func(*big.Rat) (*big.Rat, error)) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.noUnits(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	x, _ := exactRat(r.stack[0])
+	z, err := f(x)
+	if err != nil {
+		return err
+	}
+
+	r.setX(NewRat(z))
+	return nil
+}
+
+func (r *RpnCalc) ratBinaryOp(f func(*big.Rat, *big.Rat) (*big.Rat, error)) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.noUnits(2); err != nil {
+		return err
+	}
+	if err := r.noTimes(2); err != nil {
+		return err
+	}
+
+	y, _ := exactRat(r.stack[1])
+	x, _ := exactRat(r.stack[0])
+	z, err := f(y, x)
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(NewRat(z))
+	return nil
+}
+
+// ratPow calculates y**x for an integer x
+func ratPow(y, x *big.Rat) (*big.Rat, error) {
+	if !x.Num().IsInt64() || x.Num().Int64() > 1<<16 || x.Num().Int64() < -1<<16 {
+		return nil, errOverflow
+	}
+
+	n := x.Num().Int64()
+	if n < 0 {
+		if y.Sign() == 0 {
+			return nil, errDivisionByZero
+		}
+		y = new(big.Rat).Inv(y)
+		n = -n
+	}
+
+	e := big.NewInt(n)
+	num := new(big.Int).Exp(y.Num(), e, nil)
+	den := new(big.Int).Exp(y.Denom(), e, nil)
+	return new(big.Rat).SetFrac(num, den), nil
+}
+
+// limitDenominator returns the closest fraction to q with a denominator
+// of at most max, using continued fractions
+func limitDenominator(q *big.Rat, max *big.Int) *big.Rat {
+	if q.Denom().Cmp(max) <= 0 {
+		return new(big.Rat).Set(q)
+	}
+
+	p0, q0, p1, q1 := big.NewInt(0), big.NewInt(1), big.NewInt(1), big.NewInt(0)
+	n, d := new(big.Int).Set(q.Num()), new(big.Int).Set(q.Denom())
+	for {
+		a := new(big.Int).Div(n, d)
+		q2 := new(big.Int).Add(q0, new(big.Int).Mul(a, q1))
+		if q2.Cmp(max) > 0 {
+			break
+		}
+		p0, q0, p1, q1 = p1, q1, new(big.Int).Add(p0, new(big.Int).Mul(a, p1)), q2
+		n, d = d, new(big.Int).Sub(n, new(big.Int).Mul(a, d))
+	}
+
+	k := new(big.Int).Div(new(big.Int).Sub(max, q0), q1)
+	b1 := new(big.Rat).SetFrac(
+		new(big.Int).Add(p0, new(big.Int).Mul(k, p1)),
+		new(big.Int).Add(q0, new(big.Int).Mul(k, q1)))
+	b2 := new(big.Rat).SetFrac(p1, q1)
+
+	d1 := new(big.Rat).Abs(new(big.Rat).Sub(b1, q))
+	d2 := new(big.Rat).Abs(new(big.Rat).Sub(b2, q))
+	if d2.Cmp(d1) <= 0 {
+		return b2
+	}
+	return b1
+}
+
+func opToFraction(r *RpnCalc, _ string) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	v := r.stack[0]
+	if v.IsRat() {
+		return nil
+	}
+
+	// the shortest decimal representation gives 1/10 for 0.1
+	var t string
+	if v.IsBig() {
+		t = v.b.Text('g', -1)
+	} else {
+		f := v.Float64()
+		if math.IsNaN(f) {
+			return errNaN
+		}
+		t = strconv.FormatFloat(f, 'g', -1, 64)
+	}
+
+	q, ok := new(big.Rat).SetString(t)
+	if !ok {
+		return errOverflow
+	}
+
+	r.setX(NewRat(q).withUnit(v.u))
+	return nil
+}
+
+func opToDecimal(r *RpnCalc, _ string) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+
+	v := r.stack[0]
+	if !v.IsRat() {
+		return nil
+	}
+
+	if r.precise() {
+		r.setX(NewBig(v.Big(r.prec)).withUnit(v.u))
+		return nil
+	}
+
+	x, err := r.value(v.Float64())
+	if err != nil {
+		return err
+	}
+
+	r.setX(x.withUnit(v.u))
+	return nil
+}
+
+func opLimitDenominator(r *RpnCalc, _ string) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+
+	if r.stack[0].u != nil {
+		return errUnits
+	}
+	max, ok := exactRat(r.stack[0])
+	if !ok || !max.IsInt() || max.Sign() < 1 {
+		return errValueNotAllowed
+	}
+	y := r.stack[1].Rat()
+	if y == nil {
+		return errNaN
+	}
+
+	u := r.stack[1].u
+	r.pop()
+	r.setX(NewRat(limitDenominator(y, max.Num())).withUnit(u))
+	return nil
+}
+
+func opFractionMode(r *RpnCalc, _ string) error {
+	r.SetFraction(true)
+	return nil
+}
+
+func opDecimalMode(r *RpnCalc, _ string) error {
+	r.SetFraction(false)
+	return nil
+}
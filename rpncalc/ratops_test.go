@@ -0,0 +1,96 @@
+package rpncalc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestFractionOps(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string // fraction, or float for values that are not fractions
+		err   error
+	}{
+		{"literal", "2/4", "1/2", nil},
+		{"negative literal", "-3/6", "-1/2", nil},
+		{"zero denominator", "1/0", "", errDivisionByZero},
+		{"add", "1/3 2/5 +", "11/15", nil},
+		{"subtract", "1/2 1/3 -", "1/6", nil},
+		{"multiply integer", "2/3 3 *", "2", nil},
+		{"divide", "1/2 1/4 /", "2", nil},
+		{"divide by zero", "1/2 0 /", "", errDivisionByZero},
+		{"power", "2/3 3 pow", "8/27", nil},
+		{"negative power", "2/3 -2 pow", "9/4", nil},
+		{"fractional power is a float", "1/4 0.5 pow", "0.5", nil},
+		{"negate", "1/2 neg", "-1/2", nil},
+		{"inverse", "-2/3 inv", "-3/2", nil},
+		{"square", "2/3 sq", "4/9", nil},
+		{"abs", "-2/3 abs", "2/3", nil},
+		{"mixed with float", "1/2 0.25 +", "0.75", nil},
+		{"other operations give floats", "1/4 sqrt", "0.5", nil},
+		{"integers stay floats", "1 3 /", "0.3333333333333333", nil},
+		{"fraction mode decimals", "fracmode 0.1 0.2 +", "3/10", nil},
+		{"fraction mode integers", "fracmode 1 3 /", "1/3", nil},
+		{"decimal mode", "fracmode decmode 0.5", "0.5", nil},
+		{"to fraction", "0.1 frac", "1/10", nil},
+		{"to fraction of integer", "3 frac", "3", nil},
+		{"to decimal", "1/4 approx", "0.25", nil},
+		{"to fraction of time", "now frac", "", errTime},
+		{"to decimal overflows", "10/3 1000 pow approx", "", errOverflow},
+		{"limit denominator", "pi 100 limden", "311/99", nil},
+		{"limit denominator of fraction", "3/7 5 limden", "2/5", nil},
+		{"limit denominator keeps small fraction", "1/3 10 limden", "1/3", nil},
+		{"limit denominator of negative", "-0.333 10 limden", "-1/3", nil},
+		{"invalid denominator limit", "pi 0 limden", "", errValueNotAllowed},
+		{"precision mode", "big128 1/3 2/5 +", "11/15", nil},
+		{"to decimal in precision mode", "big128 1/4 approx", "0.25", nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if s := r.Val().String(); s != c.exp {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestFormatFraction(t *testing.T) {
+
+	cases := []struct {
+		q   string
+		exp string
+	}{
+		{"1/3", "1/3"},
+		{"5/3", "1 2/3"},
+		{"-5/3", "-1 2/3"},
+		{"6/3", "2"},
+		{"-1/2", "-1/2"},
+	}
+
+	r := New()
+	r.SetFraction(true)
+
+	for _, c := range cases {
+		q, _ := new(big.Rat).SetString(c.q)
+		if s := r.Format(NewRat(q), 2); s != c.exp {
+			t.Errorf("%v: Expected %q, but got %q", c.q, c.exp, s)
+		}
+	}
+
+	r.SetFraction(false)
+	if s := r.Format(NewRat(big.NewRat(1, 4)), 2); s != "0.25" {
+		t.Errorf("Expected decimal text outside fraction mode, but got %q", s)
+	}
+}
@@ -1,45 +1,219 @@
 // Package rpncalc register functions
 package rpncalc
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+)
+
+// keepLastX are the names and prefixes of operators that move, copy or
+// store values without consuming them, they keep last x like on an HP
+// calculator. Register recall is handled by saveLastX.
+var keepLastX = []string{
+	"sw", "swap", "dup", "over", "rot", "-rot", "tuck", "roll", "pick", "depth",
+	"sort", "reverse", "rs", "rc", "rx", "=", "$",
+}
+
+// LastX returns the value x had before the last operator consumed it
+func (r *RpnCalc) LastX() Value {
+	return r.lastX
+}
+
+// LastY returns the value y had before the last binary operator consumed
+// it, zero after an unary operator
+func (r *RpnCalc) LastY() Value {
+	return r.lastY
+}
+
+// saveLastX saves x, and y for binary operators, before op, evaluated for
+// the token t, consumes them
+func (r *RpnCalc) saveLastX(op *Operator, t string) {
+	if op.Prefix == "rr" {
+		// recall arithmetic, like rr+1, consumes x but plain recall does not
+		if f, _ := parseRegArithmetic(regAddress(t)); f != nil {
+			r.lastX, r.lastY = r.Val(), r.zero()
+		}
+		return
+	}
+	if op.Arity < 1 || len(r.stack) < op.Arity {
+		return
+	}
+	if in(op.Prefix, keepLastX...) {
+		return
+	}
+	for _, n := range op.Names {
+		if in(n, keepLastX...) {
+			return
+		}
+	}
+
+	r.lastX, r.lastY = r.stack[0], r.zero()
+	if op.Arity > 1 {
+		r.lastY = r.stack[1]
+	}
+}
+
+func opLastX(r *RpnCalc, _ string) error {
+	r.push(r.lastX)
+	return nil
+}
+
+func opLastY(r *RpnCalc, _ string) error {
+	r.push(r.lastY)
+	return nil
+}
+
+// regArithmetic are the operators of register arithmetic, like rs+1
+var regArithmetic = map[byte]func(*RpnCalc, string) error{
+	'+': opAddition,
+	'-': opSubtraction,
+	'*': opMultiplication,
+	'/': opDivision,
+}
 
 func dynOpRegStore(r *RpnCalc, t string) error {
-	reg, err := parseReg(t)
+	if reg := r.parseTVMReg(t); reg != nil {
+		return r.tvmStore(reg)
+	}
+
+	f, addr := parseRegArithmetic(regAddress(t))
+	reg, err := r.regIndex(addr)
 	if err != nil {
-		return errInvalidRegister
+		return err
 	}
 
-	if reg < 0 || reg >= len(r.regs) {
-		return errInvalidRegister
+	if f == nil {
+		r.regs[reg] = r.Val()
+		return nil
 	}
-	r.regs[reg] = r.stack[0]
 
+	// storage arithmetic, the register is y and x is x
+	v, err := r.reduce([]Value{r.regs[reg], r.Val()}, f)
+	if err != nil {
+		return err
+	}
+	r.regs[reg] = v
 	return nil
 }
 
 func dynOpRegRestore(r *RpnCalc, t string) error {
-	reg, err := parseReg(t)
+	if reg := r.parseTVMReg(t); reg != nil {
+		return r.pushFloat(*reg)
+	}
+
+	f, addr := parseRegArithmetic(regAddress(t))
+	reg, err := r.regIndex(addr)
 	if err != nil {
-		return errInvalidRegister
+		return err
 	}
 
-	if reg < 0 || reg >= len(r.regs) {
-		return errInvalidRegister
+	if f == nil {
+		r.push(r.regs[reg])
+		return nil
 	}
 
-	enter(r.stack, r.regs[reg])
+	// recall arithmetic, x is y and the register is x
+	v, err := r.reduce([]Value{r.Val(), r.regs[reg]}, f)
+	if err != nil {
+		return err
+	}
+	r.setX(v)
 	return nil
 }
 
 func dynOpRegClear(r *RpnCalc, t string) error {
-	reg, err := parseReg(t)
+	if reg := r.parseTVMReg(t); reg != nil {
+		*reg = 0
+		return nil
+	}
+
+	reg, err := r.regIndex(regAddress(t))
 	if err != nil {
-		return errInvalidRegister
+		return err
 	}
 
 	return r.ClearReg(reg)
 }
 
+func dynOpRegExchange(r *RpnCalc, t string) error {
+	reg, err := r.regIndex(regAddress(t))
+	if err != nil {
+		return err
+	}
+
+	x := r.Val()
+	r.setX(r.regs[reg])
+	r.regs[reg] = x
+	return nil
+}
+
+// parseTVMReg returns the time value of money register named in t, or nil
+func (r *RpnCalc) parseTVMReg(t string) *float64 {
+	return r.tvmReg(regAddress(t))
+}
+
+// regAddress returns the register address after the two letter prefix
+func regAddress(t string) string {
+	if len(t) < 2 {
+		return ""
+	}
+	return t[2:]
+}
+
+// parseRegArithmetic splits a register address like +1 in the operator of
+// register arithmetic, or nil, and the address
+func parseRegArithmetic(a string) (func(*RpnCalc, string) error, string) {
+	if len(a) > 0 {
+		if f, ok := regArithmetic[a[0]]; ok {
+			return f, a[1:]
+		}
+	}
+	return nil, a
+}
+
+// regIndex returns the register index of an address. The address is a
+// register number, @ for the index in x, which is removed from the stack,
+// or @ and a register number for the index in that register.
+func (r *RpnCalc) regIndex(a string) (int, error) {
+	switch {
+	case a == "@":
+		reg, err := r.valueIndex(r.Val())
+		if err != nil {
+			return 0, err
+		}
+		r.pop()
+		return reg, nil
+	case len(a) > 1 && a[0] == '@':
+		reg, err := r.regIndex(a[1:])
+		if err != nil {
+			return 0, err
+		}
+		return r.valueIndex(r.regs[reg])
+	}
+
+	if a == "" || a[0] < '0' || a[0] > '9' {
+		return 0, errInvalidRegister
+	}
+	reg, err := parseReg("rs" + a)
+	if err != nil || reg >= len(r.regs) {
+		return 0, errInvalidRegister
+	}
+	return reg, nil
+}
+
+// valueIndex returns the value v as a register index
+func (r *RpnCalc) valueIndex(v Value) (int, error) {
+	if v.IsComplex() || v.IsTime() || v.Unit() != nil {
+		return 0, errInvalidRegister
+	}
+
+	f := v.Float64()
+	if f != math.Trunc(f) || f < 0 || f >= float64(len(r.regs)) {
+		return 0, errInvalidRegister
+	}
+	return int(f), nil
+}
+
 func parseReg(t string) (int, error) {
 	if len(t) < 2 {
 		return 0, errInvalidRegister
@@ -23,7 +23,7 @@ func TestRegParseAndStore(t *testing.T) {
 		r := New()
 
 		// Add value to be stored first in stack
-		r.stack[0] = c.val
+		r.stack[0] = NewFloat(c.val)
 
 		// Execute store command
 		err := dynOpRegStore(r, c.cmd)
@@ -32,7 +32,7 @@ func TestRegParseAndStore(t *testing.T) {
 		}
 
 		// Check for value in register
-		got := r.regs[c.reg]
+		got := r.regs[c.reg].Float64()
 		if c.exp != got {
 			t.Errorf("Case %v: Expected value %v in register %v, but got %v", c.name, c.exp, c.reg, got)
 		}
@@ -59,7 +59,7 @@ func TestRegParseAndRetrieve(t *testing.T) {
 		r := New()
 
 		// Store expected value in register
-		r.regs[c.reg] = c.exp
+		r.regs[c.reg] = NewFloat(c.exp)
 
 		// Execute store command
 		err := dynOpRegRestore(r, c.cmd)
@@ -68,7 +68,7 @@ func TestRegParseAndRetrieve(t *testing.T) {
 		}
 
 		// Check for value in register
-		got := r.stack[0]
+		got := r.stack[0].Float64()
 		if c.exp != got {
 			t.Errorf("Case %v: Expected value %v in register %v, but got %v", c.name, c.exp, c.reg, got)
 		}
@@ -95,7 +95,7 @@ func TestRegParseAndClear(t *testing.T) {
 		r := New()
 
 		// Store expected value in register
-		r.regs[c.reg] = c.val
+		r.regs[c.reg] = NewFloat(c.val)
 
 		// Execute store command
 		err := dynOpRegClear(r, c.cmd)
@@ -104,10 +104,130 @@ func TestRegParseAndClear(t *testing.T) {
 		}
 
 		// Check for value in register
-		got := r.stack[0]
+		got := r.stack[0].Float64()
 		if 0.0 != got {
 			t.Errorf("Case %v: Expected value to be cleared in register %v, but got %v", c.name, c.reg, got)
 		}
 	}
 
 }
+
+func TestLastX(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		exp   string // value pushed by lastx or lasty
+	}{
+		{"binary operator", "2 3 - lastx", "3"},
+		{"binary operator y", "2 3 - lasty", "2"},
+		{"unary operator", "9 sqrt lastx", "9"},
+		{"unary operator y", "5 9 sqrt lasty", "0"},
+		{"latest operator", "2 3 + 4 * lastx", "4"},
+		{"drop", "7 drop lastx", "7"},
+		{"swap keeps last x", "2 3 + 1 swap lastx", "3"},
+		{"store keeps last x", "2 3 + rs0 lastx", "3"},
+		{"recall keeps last x", "2 3 + rr0 lastx", "3"},
+		{"recall arithmetic saves last x", "7 rs1 2 3 + rr*1 lastx", "5"},
+		{"recall arithmetic last y", "7 rs1 2 3 + rr*1 lasty", "0"},
+		{"store arithmetic keeps last x", "2 3 + 4 rs+1 lastx", "3"},
+		{"units", "1 m 50 cm + lastx", "50 cm"},
+		{"none", "lastx", "0"},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		if err := r.Evaluate(c.input); err != nil {
+			t.Errorf("%q: Unexpected error %v", c.name, err)
+			continue
+		}
+		if s := r.Val().String(); s != c.exp {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestLastXRollback(t *testing.T) {
+	r := New()
+
+	if err := r.Evaluate("2 3 +"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := r.Evaluate("4 * foo"); err != errUnknownInput {
+		t.Fatalf("Expected error %v, but got %v", errUnknownInput, err)
+	}
+	if s := r.LastX().String(); s != "3" {
+		t.Errorf("Expected last x to be rolled back to 3, but got %v", s)
+	}
+	if err := r.Evaluate("4 *"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := r.Evaluate("undo"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if s := r.LastX().String(); s != "3" {
+		t.Errorf("Expected last x to be undone to 3, but got %v", s)
+	}
+
+	r.ClearRegs()
+	if s := r.LastX().String(); s != "0" {
+		t.Errorf("Expected last x to be cleared, but got %v", s)
+	}
+}
+
+func TestRegArithmetic(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		reg   int    // register to check
+		exp   string // expected value in the register
+		x     string // expected value in pos 0
+		err   error
+	}{
+		{"store add", "5 rs1 3 rs+1", 1, "8", "3", nil},
+		{"store subtract", "5 rs1 3 rs-1", 1, "2", "3", nil},
+		{"store multiply", "5 rs1 3 rs*1", 1, "15", "3", nil},
+		{"store divide", "6 rs1 3 rs/1", 1, "2", "3", nil},
+		{"store divide by zero", "6 rs1 0 rs/1", 1, "", "", errDivisionByZero},
+		{"store units", "1 m rs1 50 cm rs+1", 1, "1.5 m", "50 cm", nil},
+		{"recall add", "5 rs1 3 rr+1", 1, "5", "8", nil},
+		{"recall subtract", "5 rs1 3 rr-1", 1, "5", "-2", nil},
+		{"recall multiply", "5 rs1 3 rr*1", 1, "5", "15", nil},
+		{"recall divide", "5 rs1 10 rr/1", 1, "5", "2", nil},
+		{"exchange", "5 rs1 7 rx1", 1, "7", "5", nil},
+		{"indirect store", "42 3 rs@", 3, "42", "42", nil},
+		{"indirect recall", "42 rs3 3 rr@", 3, "42", "42", nil},
+		{"indirect clear", "42 rs3 3 rc@", 3, "0", "42", nil},
+		{"indirect exchange", "42 rs3 7 3 rx@", 3, "7", "42", nil},
+		{"register indirect", "3 rs2 42 rs@2", 3, "42", "42", nil},
+		{"register indirect arithmetic", "3 rs2 40 rs3 2 rs+@2", 3, "42", "2", nil},
+		{"indirect index too big", "1 10 rs@", 0, "", "", errInvalidRegister},
+		{"indirect index negative", "1 -1 rs@", 0, "", "", errInvalidRegister},
+		{"indirect index not integer", "1 1.5 rr@", 0, "", "", errInvalidRegister},
+		{"indirect index unit", "1 1 m rr@", 0, "", "", errInvalidRegister},
+		{"register indirect too big", "10 rs2 rr@2", 0, "", "", errInvalidRegister},
+		{"missing register", "1 rs+", 0, "", "", errInvalidRegister},
+		{"signed register", "1 rc+1", 0, "", "", errInvalidRegister},
+		{"exchange invalid", "1 rx10", 0, "", "", errInvalidRegister},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if s := r.Regs()[c.reg].String(); s != c.exp {
+			t.Errorf("%q: Expected %v in register %v, but got %v", c.name, c.exp, c.reg, s)
+		}
+		if s := r.Val().String(); s != c.x {
+			t.Errorf("%q: Expected %v in pos 0, but got %v", c.name, c.x, s)
+		}
+	}
+}
@@ -4,157 +4,744 @@ package rpncalc
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // RpnCalcer defines the interface for a RpnCalc
 type RpnCalcer interface {
 	Evaluate(string) error
-	Val() (float64, error)
-	Stack() []float64
-	Regs() []float64
-	Log() []string
+	Val() Value
+	Stack() []Value
+	Regs() []Value
+	Log() []LogEntry
+	Replay([]LogEntry) error
 	ClearVal()
 	ClearStack()
 	ClearReg(i int) error
 	ClearRegs()
+	Vars() []Variable
+	ClearVar(name string) error
+	ClearVars()
+	LastX() Value
+	LastY() Value
+	Stats() Stats
+	ClearStats()
+	TVM() TVM
+	ClearTVM()
+	Amortize(from, to int) ([]Amortization, error)
 	ClearLog()
+	SetPrecision(bits uint)
+	SetUndoDepth(depth int)
+	SetUndoTokens(perToken bool)
+	SetStepLimit(limit int)
+	SetStrict(strict bool)
+	SetStackSize(size int) error
+	SetAngleMode(m AngleMode)
+	SetBase(b Base)
+	SetWordSize(bits int) error
+	SetSigned(signed bool)
+	SetComplex(on bool)
+	SetPolar(on bool)
+	SetFraction(on bool)
+	SetZone(name string) error
+	Depth() int
+	Settings() Settings
 	//Operators() []
 }
 
 const (
 	newStackSize = 4
+	minStackSize = 2
 	newRegsSize  = 10
 	newLogSize   = 0
+	newUndoDepth = 100
+	maxCallDepth = 1000
+	maxFillDepth = 1000 // deepest position filled with zeros on a dynamic stack
+	newStepLimit = 1000000
+	newWordSize  = 64
+
+	// MaxPrecision is the largest number of mantissa bits in precision mode
+	MaxPrecision = 4096
 )
 
 var (
-	errIndexOutOfRange = errors.New("index out of range")
-	errNaN             = errors.New("not a number")
-	errOverflow        = errors.New("overflow")
-	errDivisionByZero  = errors.New("division by zero")
-	errInvalidRegister = errors.New("invalid register")
-	errUnknownInput    = errors.New("unknown input")
-	errValueNotAllowed = errors.New("value not allowed")
-	errNoBinaryNumber  = errors.New("value is not a binary number")
+	errIndexOutOfRange   = errors.New("index out of range")
+	errNaN               = errors.New("not a number")
+	errOverflow          = errors.New("overflow")
+	errDivisionByZero    = errors.New("division by zero")
+	errInvalidRegister   = errors.New("invalid register")
+	errUnknownInput      = errors.New("unknown input")
+	errValueNotAllowed   = errors.New("value not allowed")
+	errNothingToUndo     = errors.New("nothing to undo")
+	errNothingToRedo     = errors.New("nothing to redo")
+	errInvalidDefinition = errors.New("invalid word definition")
+	errInvalidName       = errors.New("invalid name")
+	errUnknownWord       = errors.New("unknown word")
+	errCallDepth         = errors.New("call depth exceeded")
+	errInvalidControl    = errors.New("invalid control structure")
+	errStepLimit         = errors.New("step limit exceeded")
+	errInvalidOperator   = errors.New("invalid operator")
+	errNameCollision     = errors.New("name collision")
+	errStackUnderflow    = errors.New("stack underflow")
+	errDomain            = errors.New("value outside domain")
+	errNoStatistics      = errors.New("not enough statistics data")
+	errNotInteger        = errors.New("value is not an integer")
+	errComplex           = errors.New("operation not supported for complex values")
+	errUnits             = errors.New("operation not supported for values with units")
+	errDimensionMismatch = errors.New("dimension mismatch")
+	errUnknownUnit       = errors.New("unknown unit")
+	errTime              = errors.New("operation not supported for dates")
+	errUnknownZone       = errors.New("unknown time zone")
+	errNoSolution        = errors.New("no solution found")
+	errUnknownVariable   = errors.New("unknown variable")
 )
 
 // RpnCalc implements a RPN calculator adhering to the RpnCalcer interface
 type RpnCalc struct {
-	stack []float64
-	regs  []float64
-	log   []string
+	stack   []Value
+	depth   int  // number of values entered on the stack
+	strict  bool // fail instead of using zeros when the stack is too shallow
+	dynamic bool // the stack grows and shrinks with its values, no fixed size
+	regs    []Value
+	vars    map[string]Value // named variables
+	lastX   Value            // x before the last operator consumed it
+	lastY   Value            // y before the last binary operator consumed it
+	log     []LogEntry
+	lines   int       // number of evaluated input lines
+	prec    uint      // mantissa bits in precision mode, zero in float64 mode
+	angle   AngleMode // unit of angles for trigonometric operators
+	stats   Stats     // statistics summation registers
+	tvm     TVM       // time value of money registers
+
+	base     Base // display base
+	wordSize int  // integer word size in bits
+	signed   bool // integers are two's complement signed
+
+	complexResults bool // real operations outside their domain give complex results
+	polar          bool // display complex values in polar form
+	fraction       bool // enter decimals as fractions and display mixed numbers
+
+	zone *time.Location // time zone of date literals and new dates
+
+	undos      []snapshot
+	redos      []snapshot
+	undoDepth  int
+	undoTokens bool // record undo history per token instead of per line
+
+	words map[string][]string // user defined words
+	calls int                 // depth of user defined word calls and control blocks
+
+	steps     int // number of tokens evaluated for the current input
+	stepLimit int // zero means no limit
+}
+
+// Settings contains the current engine configuration
+type Settings struct {
+	Precision  uint      `json:"precision"` // zero means float64 mode
+	UndoDepth  int       `json:"undodepth"`
+	UndoTokens bool      `json:"undotokens"`
+	StepLimit  int       `json:"steplimit"` // zero means no limit
+	Strict     bool      `json:"strict"`
+	StackSize  int       `json:"stacksize"` // zero means a dynamic stack
+	Angle      AngleMode `json:"angle"`
+	Base       Base      `json:"base"`
+	WordSize   int       `json:"wordsize"`
+	Signed     bool      `json:"signed"`
+	Complex    bool      `json:"complex"`
+	Polar      bool      `json:"polar"`
+	Fraction   bool      `json:"fraction"`
+	Zone       string    `json:"zone"`
 }
 
 // New creates a new RpnCalc with default settings
 func New() *RpnCalc {
 	r := &RpnCalc{}
 
-	r.stack = make([]float64, newStackSize)
-	r.regs = make([]float64, newRegsSize)
-	r.log = []string{}
+	r.stack = make([]Value, newStackSize)
+	r.regs = make([]Value, newRegsSize)
+	r.vars = map[string]Value{}
+	r.log = []LogEntry{}
+	r.undoDepth = newUndoDepth
+	r.words = map[string][]string{}
+	r.stepLimit = newStepLimit
+	r.wordSize = newWordSize
+	r.signed = true
+	r.zone = time.Local
 
 	return r
 }
 
-// Evaluate takes some input, number, operator, or command, and tries to parse it
+// Evaluate takes some input, number, operator, or command, and tries to parse it.
+// The input is evaluated as a transaction, if any token fails the stack,
+// registers and log are rolled back to their state before the input.
 func (r *RpnCalc) Evaluate(input string) error {
 
 	input = strings.TrimSpace(input)
+	r.lines++
 
 	// Check if comment
 	if strings.HasPrefix(input, "#") {
-		r.log = append(r.log, input)
+		r.addLog(LogComment, input)
 		return nil
 	}
 
 	// Split input into tokens
-	ts := strings.Split(input, " ")
-	for _, t := range ts {
-		t = strings.TrimSpace(t)
-		if t == "" {
+	ts := strings.Fields(input)
+
+	tx := r.begin()
+	r.steps = 0
+	err := r.evaluate(ts)
+	if err != nil {
+		r.rollback(tx)
+		r.addLog(LogError, fmt.Sprintf("%v: %v", err, input))
+		return err
+	}
+
+	// Record undo history for the whole line, unless it walks the history
+	if !r.undoTokens && input != "" && !containsUndoOp(ts) {
+		r.record(tx.state)
+	}
+
+	return nil
+}
+
+// evaluate evaluates tokens one by one, stopping at the first error
+func (r *RpnCalc) evaluate(ts []string) error {
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+
+		// Only tokens from the input are logged and recorded, not the
+		// tokens in the body of user defined words and control blocks
+		top := r.calls == 0
+
+		if err := r.step(); err != nil {
+			return err
+		}
+
+		// Record undo history for the token
+		if top && r.undoTokens && !isUndoOp(t) {
+			r.record(r.snapshot())
+		}
+
+		// Handle word definitions and removals
+		switch t {
+		case ":":
+			n, err := r.define(ts[i:])
+			if err != nil {
+				return err
+			}
+			if top {
+				for _, d := range ts[i : i+n] {
+					r.addLog(LogInput, d)
+				}
+			}
+			i += n - 1
+			continue
+		case "if", "times", "while":
+			n, err := r.control(ts[i:])
+			if err != nil {
+				return err
+			}
+			if top {
+				for _, c := range ts[i : i+n] {
+					r.addLog(LogInput, c)
+				}
+				r.addLog(LogResult, r.Val().String())
+			}
+			i += n - 1
+			continue
+		case "else", "then", "do", "end":
+			return errInvalidControl
+		case "forget":
+			if i+1 >= len(ts) {
+				return errUnknownWord
+			}
+			if err := r.forget(ts[i+1]); err != nil {
+				return err
+			}
+			if top {
+				r.addLog(LogInput, t)
+				r.addLog(LogInput, ts[i+1])
+			}
+			i++
+			continue
+		case "to":
+			if i+1 >= len(ts) {
+				return errUnknownUnit
+			}
+			if err := r.convertTo(ts[i+1]); err != nil {
+				return err
+			}
+			if top {
+				r.addLog(LogInput, t)
+				r.addLog(LogInput, ts[i+1])
+				r.addLog(LogResult, r.Val().String())
+			}
+			i++
+			continue
+		case "tz":
+			if i+1 >= len(ts) {
+				return errUnknownZone
+			}
+			if err := r.convertZone(ts[i+1]); err != nil {
+				return err
+			}
+			if top {
+				r.addLog(LogInput, t)
+				r.addLog(LogInput, ts[i+1])
+				r.addLog(LogResult, r.Val().String())
+			}
+			i++
+			continue
+		case "zone":
+			if i+1 >= len(ts) {
+				return errUnknownZone
+			}
+			if err := r.SetZone(ts[i+1]); err != nil {
+				return err
+			}
+			if top {
+				r.addLog(LogInput, t)
+				r.addLog(LogInput, ts[i+1])
+			}
+			i++
 			continue
 		}
 
 		// Handle constants
 		found := r.pushConstant(t)
 		if found {
+			if top {
+				r.addLog(LogInput, t)
+			}
+			continue
+		}
+
+		// Handle variables
+		if r.pushVar(t) {
+			if top {
+				r.addLog(LogInput, t)
+			}
 			continue
 		}
 
-		// Try to parse a float64
-		val, err := strconv.ParseFloat(t, 64)
+		// Try to parse a number
+		val, err := r.parseNumber(t)
 		if err == nil {
 			// Token is a number
-			r.log = append(r.log, fmt.Sprintf("%v", val))
-			r.stack = enter(r.stack, val)
+			if top {
+				r.addLog(LogInput, t)
+			}
+			r.push(val)
 			continue
 		}
+		if err == errOverflow || err == errDivisionByZero || err == errNaN {
+			return err
+		}
 
-		// Match static operators, unary and binary
-		found, err = executeOp(r, t)
+		// Match static operators, unary and binary, and user defined words
+		op := findOp(t)
+		body, isWord := r.words[t]
+		u := findUnit(t)
+		if u != nil && op != nil && op.Type == DynamicOp {
+			// unit names, like minute, win over operator prefixes
+			op = nil
+		}
+		if op == nil && !isWord && u == nil {
+			// Unknown input
+			return errUnknownInput
+		}
+
+		if top {
+			r.addLog(LogInput, t)
+		}
+
+		switch {
+		case op != nil:
+			err = r.need(op.Arity)
+			if err == nil {
+				r.saveLastX(op, t)
+				err = op.Handler(r, t)
+			}
+		case isWord:
+			err = r.call(body)
+		default:
+			err = r.applyUnit(u)
+		}
 		if err != nil {
 			return err
 		}
-		if found {
-			r.log = append(r.log, fmt.Sprintf(">> %v", r.stack[0]))
-			continue
+
+		if top {
+			r.addLog(LogResult, r.Val().String())
 		}
+	}
+
+	return nil
+}
+
+// transaction holds what is needed to roll back a failed evaluation
+type transaction struct {
+	state   snapshot
+	dynamic bool // the stack model, which undo does not restore
+	strict  bool
+	cplx    bool
+	polar   bool
+	zone    *time.Location
+	undos   []snapshot
+	redos   []snapshot
+	logSize int
+}
 
-		// Unknown input
-		r.log = append(r.log, "Unknown input: "+t)
-		return errUnknownInput
+func (r *RpnCalc) begin() transaction {
+	return transaction{
+		state:   r.snapshot(),
+		dynamic: r.dynamic,
+		strict:  r.strict,
+		cplx:    r.complexResults,
+		polar:   r.polar,
+		zone:    r.zone,
+		undos:   append([]snapshot{}, r.undos...),
+		redos:   append([]snapshot{}, r.redos...),
+		logSize: len(r.log),
 	}
+}
+
+func (r *RpnCalc) rollback(tx transaction) {
+	r.dynamic, r.stack = tx.dynamic, tx.state.stack
+	r.strict, r.complexResults, r.polar, r.zone = tx.strict, tx.cplx, tx.polar, tx.zone
+	r.restore(tx.state)
+	r.undos = tx.undos
+	r.redos = tx.redos
+	r.log = r.log[:tx.logSize]
+}
 
+// lookupOp is findOp for the operator handlers, like the variable store,
+// that check names against the operators. It is set in init to break the
+// initialization cycle through the operators table.
+var lookupOp func(string) *Operator
+
+func init() {
+	lookupOp = findOp
+}
+
+// findOp returns the operator matching a token, or nil. Static operators
+// are matched before the prefixes of dynamic operators, so a name like sum
+// is not taken as the prefix of sum5.
+func findOp(t string) *Operator {
+	for i, op := range operators {
+		if in(t, op.Names...) {
+			return &operators[i]
+		}
+	}
+	for i, op := range operators {
+		if op.Prefix != "" && strings.HasPrefix(t, op.Prefix) {
+			return &operators[i]
+		}
+	}
 	return nil
 }
 
-func executeOp(r *RpnCalc, t string) (found bool, err error) {
-	for _, op := range operators {
-		if in(t, op.Names...) || (op.Prefix != "" && strings.HasPrefix(t, op.Prefix)) {
-			r.log = append(r.log, t)
-			err = op.Handler(r, t)
-			if err != nil {
-				r.log = append(r.log, fmt.Sprintf("[%v]", err))
-				return true, err
+// parseNumber parses a number token according to the current mode
+func (r *RpnCalc) parseNumber(t string) (Value, error) {
+	if v, ok, err := r.parseLiteral(t); ok {
+		return v, err
+	}
+	if v, ok, err := r.parseRat(t); ok {
+		return v, err
+	}
+	if strings.HasSuffix(t, "i") {
+		if c, err := strconv.ParseComplex(t, 128); err == nil {
+			return r.complexValue(c)
+		}
+	}
+
+	f, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		if v, ok := r.parseTime(t); ok {
+			return v, nil
+		}
+		return Value{}, err
+	}
+	if r.prec == 0 {
+		return NewFloat(f), nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// big.Float can hold infinities, but not calculate with them
+		return r.value(f)
+	}
+
+	b, _, err := big.ParseFloat(t, 10, r.prec, big.ToNearestEven)
+	if err != nil {
+		return Value{}, err
+	}
+	return NewBig(b), nil
+}
+
+// isNumber returns true if t is parsed as a number, or fails as a number
+// that is out of range
+func (r *RpnCalc) isNumber(t string) bool {
+	_, err := r.parseNumber(t)
+	return err == nil || err == errOverflow || err == errDivisionByZero || err == errNaN
+}
+
+// value converts a float64 result to a value in the current mode
+func (r *RpnCalc) value(f float64) (Value, error) {
+	if math.IsNaN(f) {
+		return Value{}, errNaN
+	}
+	if math.IsInf(f, 0) {
+		return Value{}, errOverflow
+	}
+	if r.prec == 0 {
+		return NewFloat(f), nil
+	}
+	return NewBig(new(big.Float).SetPrec(r.prec).SetFloat64(f)), nil
+}
+
+// newBig returns a zero big.Float with the current precision
+func (r *RpnCalc) newBig() *big.Float {
+	return new(big.Float).SetPrec(r.prec)
+}
+
+// precise returns true if the engine is in precision mode
+func (r *RpnCalc) precise() bool {
+	return r.prec > 0
+}
+
+// SetPrecision sets the number of mantissa bits used for values. Zero
+// switches to float64 mode. All values on the stack and in the registers
+// are converted to the new mode.
+func (r *RpnCalc) SetPrecision(bits uint) {
+	if bits > MaxPrecision {
+		bits = MaxPrecision
+	}
+	r.prec = bits
+
+	convert := func(vs []Value) {
+		for i, v := range vs {
+			if v.IsComplex() || v.IsRat() || v.IsTime() {
+				continue
 			}
-			return true, nil
+			if bits == 0 {
+				vs[i] = NewFloat(v.Float64()).withUnit(v.u)
+				continue
+			}
+			vs[i] = NewBig(v.Big(bits)).withUnit(v.u)
 		}
 	}
-	return false, nil
+	convert(r.stack)
+	convert(r.regs)
+	for n, v := range r.vars {
+		vs := []Value{v}
+		convert(vs)
+		r.vars[n] = vs[0]
+	}
+}
+
+// SetUndoDepth sets the number of states kept in the undo history. Zero
+// disables undo.
+func (r *RpnCalc) SetUndoDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	r.undoDepth = depth
+
+	if len(r.undos) > depth {
+		r.undos = r.undos[len(r.undos)-depth:]
+	}
+	if len(r.redos) > depth {
+		r.redos = r.redos[len(r.redos)-depth:]
+	}
+}
+
+// SetUndoTokens makes undo step one token at a time if perToken is true,
+// otherwise one input line at a time.
+func (r *RpnCalc) SetUndoTokens(perToken bool) {
+	r.undoTokens = perToken
+}
+
+// SetStepLimit sets the maximum number of tokens evaluated for one input,
+// including tokens in words and loops. Zero means no limit.
+func (r *RpnCalc) SetStepLimit(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+	r.stepLimit = limit
+}
+
+// SetStrict makes operators fail with a stack underflow error when there are
+// fewer values on the stack than they use. When not strict, missing values
+// are zeros.
+func (r *RpnCalc) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// SetStackSize sets the stack model. A size of zero gives a dynamic stack
+// that grows with the entered values. Otherwise the stack has a fixed size,
+// values are dropped from the top when it is full and the top value is
+// copied when values are removed. The values on the stack are kept, as far
+// as they fit.
+func (r *RpnCalc) SetStackSize(size int) error {
+	if size != 0 && size < minStackSize {
+		return errValueNotAllowed
+	}
+
+	r.dynamic = size == 0
+	r.stack, r.depth = r.resize(r.stack, r.depth)
+	if !r.dynamic && len(r.stack) != size {
+		r.stack, r.depth = resizeFixed(r.stack, r.depth, size, r.zero())
+	}
+
+	return nil
+}
+
+// resize adapts a stack and depth from any stack model to the current model
+func (r *RpnCalc) resize(s []Value, depth int) ([]Value, int) {
+	if depth > len(s) {
+		depth = len(s)
+	}
+	if r.dynamic {
+		return append([]Value{}, s[:depth]...), depth
+	}
+	if len(s) == r.stackSize() {
+		return s, depth
+	}
+	return resizeFixed(s, depth, r.stackSize(), r.zero())
+}
+
+// stackSize returns the size of a fixed stack, or zero for a dynamic stack
+func (r *RpnCalc) stackSize() int {
+	if r.dynamic {
+		return 0
+	}
+	return len(r.stack)
+}
+
+func resizeFixed(s []Value, depth int, size int, zero Value) ([]Value, int) {
+	fixed := make([]Value, size)
+	for i := range fixed {
+		fixed[i] = zero
+		if i < len(s) {
+			fixed[i] = s[i]
+		}
+	}
+	if depth > size {
+		depth = size
+	}
+	return fixed, depth
+}
+
+// SetAngleMode sets the unit of angles used by the trigonometric operators
+func (r *RpnCalc) SetAngleMode(m AngleMode) {
+	r.angle = m
+}
+
+// Depth returns the number of values entered on the stack
+func (r *RpnCalc) Depth() int {
+	return r.depth
+}
+
+// Settings returns the current engine configuration
+func (r *RpnCalc) Settings() Settings {
+	return Settings{
+		Precision:  r.prec,
+		UndoDepth:  r.undoDepth,
+		UndoTokens: r.undoTokens,
+		StepLimit:  r.stepLimit,
+		Strict:     r.strict,
+		StackSize:  r.stackSize(),
+		Angle:      r.angle,
+		Base:       r.base,
+		WordSize:   r.wordSize,
+		Signed:     r.signed,
+		Complex:    r.complexResults,
+		Polar:      r.polar,
+		Fraction:   r.fraction,
+		Zone:       r.zone.String(),
+	}
+}
+
+// Push puts a value first on the stack, converted to the current mode.
+// Push is intended for handlers of registered operators.
+func (r *RpnCalc) Push(v Value) {
+	switch {
+	case v.IsComplex(), v.IsRat(), v.IsTime():
+	case r.precise():
+		v = NewBig(v.Big(r.prec)).withUnit(v.u)
+	default:
+		v = NewFloat(v.Float64()).withUnit(v.u)
+	}
+	r.push(v)
+}
+
+// Pop removes and returns the first value on the stack. Pop is intended for
+// handlers of registered operators.
+func (r *RpnCalc) Pop() Value {
+	return r.pop()
+}
+
+// UnaryOp replaces the first value on the stack with f applied to it. UnaryOp
+// is intended for handlers of registered operators.
+func (r *RpnCalc) UnaryOp(f func(x float64) (float64, error)) error {
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return f(x)
+	})
+}
+
+// BinaryOp replaces the first two values on the stack with f applied to
+// them, y being the second and x the first value. BinaryOp is intended for
+// handlers of registered operators.
+func (r *RpnCalc) BinaryOp(f func(y, x float64) (float64, error)) error {
+	return r.binaryOp(f)
 }
 
 // Val gets the first value on the stack, the display value
-func (r *RpnCalc) Val() float64 {
+func (r *RpnCalc) Val() Value {
+	if len(r.stack) < 1 {
+		return r.zero()
+	}
 	return r.stack[0]
 }
 
-// Stack returns the current stack of values
-func (r *RpnCalc) Stack() []float64 {
+// Stack returns the current stack of values. A fixed stack always returns
+// all positions, a dynamic stack only the entered values.
+func (r *RpnCalc) Stack() []Value {
 	return r.stack
 }
 
 // Regs returns the registers
-func (r *RpnCalc) Regs() []float64 {
+func (r *RpnCalc) Regs() []Value {
 	return r.regs
 }
 
 // Log returns the calculation log
-func (r *RpnCalc) Log() []string {
+func (r *RpnCalc) Log() []LogEntry {
 	return r.log
 }
 
 // ClearVal puts a zero value in the first position of the stack
 func (r *RpnCalc) ClearVal() {
-	r.stack[0] = 0.0
+	if len(r.stack) < 1 {
+		return
+	}
+	r.stack[0] = r.zero()
 }
 
-// ClearStack puts zero values in all positons of the stack
+// ClearStack puts zero values in all positons of a fixed stack and empties a
+// dynamic stack
 func (r *RpnCalc) ClearStack() {
+	if r.dynamic {
+		r.stack = []Value{}
+	}
 	for i := range r.stack {
-		r.stack[i] = 0.0
+		r.stack[i] = r.zero()
 	}
+	r.depth = 0
 }
 
 // ClearReg puts a zero value in the i:th position of the registers
@@ -162,44 +749,140 @@ func (r *RpnCalc) ClearReg(i int) error {
 	if i < 0 || i > len(r.regs)-1 {
 		return errInvalidRegister
 	}
-	r.regs[i] = 0.0
+	r.regs[i] = r.zero()
 	return nil
 }
 
 // ClearRegs clears all register values
 func (r *RpnCalc) ClearRegs() {
 	for i := range r.regs {
-		r.regs[i] = 0.0
+		r.regs[i] = r.zero()
 	}
+	r.lastX, r.lastY = r.zero(), r.zero()
 }
 
 // ClearLog clears the log
 func (r *RpnCalc) ClearLog() {
-	r.log = []string{}
+	r.log = []LogEntry{}
 }
 
 // Helper functions
 
-func enter(s []float64, v float64) []float64 {
+// step counts evaluation steps and fails when the step limit is reached
+func (r *RpnCalc) step() error {
+	r.steps++
+	if r.stepLimit > 0 && r.steps > r.stepLimit {
+		return errStepLimit
+	}
+	return nil
+}
+
+// need makes sure that an operator can use n values on the stack. In strict
+// mode it fails if fewer values are entered, otherwise missing values are
+// zeros. A dynamic stack is filled up with zeros.
+func (r *RpnCalc) need(n int) error {
+	if r.strict && r.depth < n {
+		return errStackUnderflow
+	}
+
+	if !r.dynamic {
+		if len(r.stack) < n {
+			return errStackUnderflow
+		}
+		return nil
+	}
+
+	for len(r.stack) < n {
+		r.stack = append(r.stack, r.zero())
+	}
+	r.depth = len(r.stack)
+	return nil
+}
+
+// push enters a value first on the stack
+func (r *RpnCalc) push(v Value) {
+	if r.dynamic {
+		r.stack = append([]Value{v}, r.stack...)
+		r.depth = len(r.stack)
+		return
+	}
+
+	r.stack = enter(r.stack, v)
+	if r.depth < len(r.stack) {
+		r.depth++
+	}
+}
+
+// pop removes and returns the first value on the stack
+func (r *RpnCalc) pop() Value {
+	if r.dynamic {
+		if len(r.stack) < 1 {
+			return r.zero()
+		}
+		v := r.stack[0]
+		r.stack = r.stack[1:]
+		r.depth = len(r.stack)
+		return v
+	}
+
+	v := r.stack[0]
+	r.stack = rolldown(r.stack)
+	if r.depth > 0 {
+		r.depth--
+	}
+	return v
+}
+
+// setX replaces the first value on the stack with the result of an operator
+func (r *RpnCalc) setX(v Value) {
+	if len(r.stack) < 1 {
+		r.stack = []Value{v}
+	} else {
+		r.stack[0] = v
+	}
+	if r.depth < 1 {
+		r.depth = 1
+	}
+}
+
+// zero returns a zero value in the current mode
+func (r *RpnCalc) zero() Value {
+	if r.prec == 0 {
+		return NewFloat(0)
+	}
+	return NewBig(r.newBig())
+}
+
+func enter(s []Value, v Value) []Value {
 	s = rollup(s)
 	s[0] = v
 	return s
 }
 
-func rollup(s []float64) []float64 {
+func rollup(s []Value) []Value {
 	for i := len(s) - 1; i > 0; i-- {
 		s[i] = s[i-1]
 	}
 	return s
 }
 
-func rolldown(s []float64) []float64 {
+func rolldown(s []Value) []Value {
 	for i := 0; i < len(s)-1; i++ {
 		s[i] = s[i+1]
 	}
 	return s
 }
 
+// Helper func to check if any token walks the undo history
+func containsUndoOp(ts []string) bool {
+	for _, t := range ts {
+		if isUndoOp(t) {
+			return true
+		}
+	}
+	return false
+}
+
 // Helper func to find matching operator name
 func in(t string, ms ...string) bool {
 	for _, m := range ms {
@@ -26,13 +26,13 @@ func TestNew(t *testing.T) {
 	}
 
 	for i := range r.stack {
-		if r.stack[i] != 0.0 {
+		if r.stack[i].Float64() != 0.0 {
 			t.Errorf("Expected stack to contain zero values, but found %v", r.stack[i])
 		}
 	}
 
 	for i := range r.regs {
-		if r.regs[i] != 0.0 {
+		if r.regs[i].Float64() != 0.0 {
 			t.Errorf("Expected regs to contain zero values, but found %v", r.regs[i])
 		}
 	}
@@ -85,17 +85,23 @@ func TestEnterVal(t *testing.T) {
 		{"regs retrive change stack",
 			[]string{"1 2 3 4 rr9"}, []float64{0, 4, 3, 2}, nil},
 		{"regs store invalid reg fails",
-			[]string{"1 2 3 4 rs99"}, []float64{4, 3, 2, 1}, errInvalidRegister},
+			[]string{"1 2 3 4", "rs99"}, []float64{4, 3, 2, 1}, errInvalidRegister},
 		{"regs clear invalid reg fails",
-			[]string{"1 2 3 4 rcx"}, []float64{4, 3, 2, 1}, errInvalidRegister},
+			[]string{"1 2 3 4", "rcx"}, []float64{4, 3, 2, 1}, errInvalidRegister},
 		{"regs retrive invalid reg fails",
-			[]string{"1 2 3 4 rrapa"}, []float64{4, 3, 2, 1}, errInvalidRegister},
+			[]string{"1 2 3 4", "rrapa"}, []float64{4, 3, 2, 1}, errInvalidRegister},
 
 		// Unknown operations
 		{"unknown op",
 			[]string{"123", "foo"}, []float64{123.0, 0.0, 0.0, 0.0}, errUnknownInput},
-		{"fail in middle",
-			[]string{"123 foo 321"}, []float64{123.0, 0.0, 0.0, 0.0}, errUnknownInput},
+		{"fail in middle rolls back line",
+			[]string{"123 foo 321"}, []float64{0.0, 0.0, 0.0, 0.0}, errUnknownInput},
+		{"fail in middle keeps previous lines",
+			[]string{"1 2", "3 4 + foo 5 *"}, []float64{2.0, 1.0, 0.0, 0.0}, errUnknownInput},
+		{"division by zero rolls back line",
+			[]string{"1 2", "3 0 /"}, []float64{2.0, 1.0, 0.0, 0.0}, errDivisionByZero},
+		{"failed register op rolls back line",
+			[]string{"1 2", "3 rs1 4 rs99"}, []float64{2.0, 1.0, 0.0, 0.0}, errInvalidRegister},
 
 		// TODO: Add testcases when new functionality comes along
 	}
@@ -127,7 +133,7 @@ func TestEnterVal(t *testing.T) {
 
 		// Check stack
 		for i := range c.stack {
-			if c.stack[i] != r.stack[i] {
+			if c.stack[i] != r.stack[i].Float64() {
 				t.Errorf("%q: Expected stack %v, but got %v", c.name, c.stack, r.stack)
 			}
 		}
@@ -144,14 +150,14 @@ func TestValAndClear(t *testing.T) {
 		t.Fatalf("Could not enter expression, got error %v", err)
 	}
 
-	val := r.Val()
+	val := r.Val().Float64()
 	if val != expVal {
 		t.Fatalf("Expected value %v, but got %v", expVal, val)
 	}
 
 	r.ClearVal()
 
-	val = r.Val()
+	val = r.Val().Float64()
 	if val != 0.0 {
 		t.Fatalf("Expected value to be cleared, but got %v", val)
 	}
@@ -159,11 +165,17 @@ func TestValAndClear(t *testing.T) {
 
 func TestLogContentAndClear(t *testing.T) {
 
-	expLog := []string{"1", "2", "+", ">> 3", "3", "+", ">> 6", "0", "inv", "[division by zero]"}
+	expLog := []string{"1", "2", "+", ">> 3", "3", "+", ">> 6", "[division by zero: 0 inv]"}
 
 	r := New()
 
-	err := r.Evaluate("1 2 + 3 + 0 inv")
+	err := r.Evaluate("1 2 + 3 +")
+	if err != nil {
+		t.Fatalf("Expected no error, got error %v", err)
+	}
+
+	// A failing line should only leave an error in the log
+	err = r.Evaluate("0 inv")
 	if err != errDivisionByZero {
 		t.Fatalf("Expected error %v, got error %v", errDivisionByZero, err)
 	}
@@ -180,6 +192,25 @@ func TestLogContentAndClear(t *testing.T) {
 	}
 }
 
+func TestEvaluateRollsBackRegisters(t *testing.T) {
+
+	r := New()
+
+	err := r.Evaluate("5 rs1")
+	if err != nil {
+		t.Fatalf("Faild to set register, got error %v", err)
+	}
+
+	err = r.Evaluate("7 rs1 rs2 foo")
+	if err != errUnknownInput {
+		t.Fatalf("Expected error %v, but got %v", errUnknownInput, err)
+	}
+
+	if r.regs[1].Float64() != 5 || r.regs[2].Float64() != 0 {
+		t.Errorf("Expected r1 = 5 and r2 = 0 after rollback, but got %v", r.regs)
+	}
+}
+
 func TestRegsAndClear(t *testing.T) {
 
 	r := New()
@@ -190,13 +221,13 @@ func TestRegsAndClear(t *testing.T) {
 	}
 
 	regs := r.Regs()
-	if regs[1] != 1 || regs[2] != 2 {
+	if regs[1].Float64() != 1 || regs[2].Float64() != 2 {
 		t.Fatalf("Bad register values expeted r1 = 1 and r2 = 2, but got %v", regs)
 	}
 
 	// Clear register 2
 	err = r.ClearReg(2)
-	if regs[2] != 0.0 {
+	if regs[2].Float64() != 0.0 {
 		t.Errorf("Expected register 2 to be cleared, but it contained %v", regs[2])
 	}
 
@@ -215,7 +246,7 @@ func TestRegsAndClear(t *testing.T) {
 	// Clear all registers
 	r.ClearRegs()
 	for i, r := range r.Regs() {
-		if r != 0.0 {
+		if r.Float64() != 0.0 {
 			t.Errorf("Reg %v contains %v, expected it to be cleared", i, r)
 		}
 	}
@@ -241,3 +272,87 @@ func TestInvalidRegisters(t *testing.T) {
 	}
 
 }
+
+func TestPushPop(t *testing.T) {
+
+	r := New()
+
+	r.Push(NewFloat(1))
+	r.Push(NewFloat(2))
+	if v := r.Pop(); v.Float64() != 2 {
+		t.Errorf("Expected to pop 2, but got %v", v)
+	}
+
+	// Pushed values are converted to the current mode
+	r.SetPrecision(64)
+	r.Push(NewFloat(3))
+	if !r.Val().IsBig() || r.Val().Float64() != 3 {
+		t.Errorf("Expected big value 3, but got %v", r.Val())
+	}
+	if v := r.Pop(); v.Float64() != 3 || r.Val().Float64() != 1 {
+		t.Errorf("Expected to pop 3 and leave 1, but got %v and %v", v, r.Val())
+	}
+}
+
+func TestDepthAndStrictMode(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		strict bool
+		input  []string
+		depth  int
+		val    float64
+		err    error
+	}{
+		{"empty", false, []string{""}, 0, 0, nil},
+		{"entered values", false, []string{"1 2"}, 2, 2, nil},
+		{"fixed stack depth is limited", false, []string{"1 2 3 4 5 6"}, 4, 6, nil},
+		{"binary op", false, []string{"1 2 +"}, 1, 3, nil},
+		{"unary op", false, []string{"4 sqrt"}, 1, 2, nil},
+		{"binary op on empty stack", false, []string{"+"}, 1, 0, nil},
+		{"restore register", false, []string{"rr1"}, 1, 0, nil},
+		{"store register", false, []string{"1 rs1"}, 1, 1, nil},
+		{"constant", false, []string{"pi pi"}, 2, 3.141592653589793, nil},
+		{"if pops", false, []string{"5 1 if 2 then"}, 2, 2, nil},
+		{"strict binary op", true, []string{"1 2 +"}, 1, 3, nil},
+		{"strict binary op underflow", true, []string{"1", "+"}, 1, 1, errStackUnderflow},
+		{"strict unary op underflow", true, []string{"neg"}, 0, 0, errStackUnderflow},
+		{"strict store underflow", true, []string{"rs1"}, 0, 0, errStackUnderflow},
+		{"strict restore", true, []string{"rr1"}, 1, 0, nil},
+		{"strict if underflow", true, []string{"if 1 then"}, 0, 0, errStackUnderflow},
+		{"strict times underflow", true, []string{"times 1 end"}, 0, 0, errStackUnderflow},
+		{"strict while underflow", true, []string{"while do end"}, 0, 0, errStackUnderflow},
+		{"strict underflow in word", true, []string{": w + ;", "1 w"}, 0, 0, errStackUnderflow},
+		{"strict clears when undone", true, []string{"1 2", "undo", "+"}, 0, 0, errStackUnderflow},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetStrict(c.strict)
+
+		var err error
+		for _, l := range c.input {
+			err = r.Evaluate(l)
+		}
+
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if r.Depth() != c.depth {
+			t.Errorf("%q: Expected depth %v, but got %v", c.name, c.depth, r.Depth())
+		}
+
+		if r.Val().Float64() != c.val {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.val, r.Val())
+		}
+	}
+
+	r := New()
+	_ = r.Evaluate("1 2 3")
+	r.ClearStack()
+	if r.Depth() != 0 {
+		t.Errorf("Expected depth 0 after clear, but got %v", r.Depth())
+	}
+}
@@ -1,6 +1,8 @@
 // Package rpncalc stack operations
 package rpncalc
 
+import "strconv"
+
 func opClearStack(r *RpnCalc, _ string) error {
 	r.ClearStack()
 	return nil
@@ -10,6 +12,81 @@ func opSwap(r *RpnCalc, _ string) error {
 	return r.stackSwap(0, 1)
 }
 
+func opDup(r *RpnCalc, _ string) error {
+	return r.stackPick(0)
+}
+
+func opDrop(r *RpnCalc, _ string) error {
+	r.pop()
+	return nil
+}
+
+func opOver(r *RpnCalc, _ string) error {
+	return r.stackPick(1)
+}
+
+func opRot(r *RpnCalc, _ string) error {
+	return r.stackRoll(2)
+}
+
+func opRotBack(r *RpnCalc, _ string) error {
+	return r.stackRollBack(2)
+}
+
+func opNip(r *RpnCalc, _ string) error {
+	x := r.pop()
+	r.setX(x)
+	return nil
+}
+
+func opTuck(r *RpnCalc, _ string) error {
+	if err := r.stackSwap(0, 1); err != nil {
+		return err
+	}
+	return r.stackPick(1)
+}
+
+func opDepth(r *RpnCalc, _ string) error {
+	v, err := r.value(float64(r.depth))
+	if err != nil {
+		return err
+	}
+	r.push(v)
+	return nil
+}
+
+func opRoll(r *RpnCalc, _ string) error {
+	n, err := r.popIndex()
+	if err != nil {
+		return err
+	}
+	return r.stackRoll(n)
+}
+
+func opPick(r *RpnCalc, _ string) error {
+	n, err := r.popIndex()
+	if err != nil {
+		return err
+	}
+	return r.stackPick(n)
+}
+
+func dynOpRoll(r *RpnCalc, t string) error {
+	n, err := parseIndex(t[4:])
+	if err != nil {
+		return err
+	}
+	return r.stackRoll(n)
+}
+
+func dynOpPick(r *RpnCalc, t string) error {
+	n, err := parseIndex(t[4:])
+	if err != nil {
+		return err
+	}
+	return r.stackPick(n)
+}
+
 func (r *RpnCalc) stackSwap(i, j int) error {
 	if i < 0 || j < 0 || i >= len(r.stack) || j >= len(r.stack) {
 		return errIndexOutOfRange
@@ -20,3 +97,73 @@ func (r *RpnCalc) stackSwap(i, j int) error {
 
 	return nil
 }
+
+// stackPick pushes a copy of the value at position n
+func (r *RpnCalc) stackPick(n int) error {
+	if err := r.needIndex(n); err != nil {
+		return err
+	}
+	r.push(r.stack[n])
+	return nil
+}
+
+// stackRoll moves the value at position n to the top of the stack
+func (r *RpnCalc) stackRoll(n int) error {
+	if err := r.needIndex(n); err != nil {
+		return err
+	}
+	v := r.stack[n]
+	copy(r.stack[1:n+1], r.stack[:n])
+	r.stack[0] = v
+	return nil
+}
+
+// stackRollBack moves the top value of the stack to position n
+func (r *RpnCalc) stackRollBack(n int) error {
+	if err := r.needIndex(n); err != nil {
+		return err
+	}
+	v := r.stack[0]
+	copy(r.stack[:n], r.stack[1:n+1])
+	r.stack[n] = v
+	return nil
+}
+
+// needIndex makes sure position n can be used and counts the values up to
+// it as entered
+func (r *RpnCalc) needIndex(n int) error {
+	if n < 0 || (!r.dynamic && n >= len(r.stack)) || r.beyondStack(n) {
+		return errIndexOutOfRange
+	}
+	if err := r.need(n + 1); err != nil {
+		return err
+	}
+	if r.depth < n+1 {
+		r.depth = n + 1
+	}
+	return nil
+}
+
+// beyondStack returns true if position n is so far beyond the values on
+// a dynamic stack that it is not filled up with zeros
+func (r *RpnCalc) beyondStack(n int) bool {
+	return n >= len(r.stack) && n >= maxFillDepth
+}
+
+// popIndex pops a stack position from the stack
+func (r *RpnCalc) popIndex() (int, error) {
+	x := r.Val().Float64()
+	if x < 0 || x != float64(int(x)) {
+		return 0, errIndexOutOfRange
+	}
+	r.pop()
+	return int(x), nil
+}
+
+func parseIndex(t string) (int, error) {
+	n, err := strconv.Atoi(t)
+	if err != nil || n < 0 {
+		return 0, errIndexOutOfRange
+	}
+	return n, nil
+}
@@ -25,7 +25,7 @@ func TestStackSwap(t *testing.T) {
 
 	for i, c := range cases {
 
-		r.stack = c.s
+		r.stack = values(c.s...)
 
 		err := r.stackSwap(c.i, c.j)
 
@@ -39,3 +39,201 @@ func TestStackSwap(t *testing.T) {
 		}
 	}
 }
+
+func TestStackModels(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		size   int // zero for dynamic stack
+		strict bool
+		input  []string
+		stack  []float64
+		err    error
+	}{
+		{"fixed drops top", 3, false, []string{"1 2 3 4"}, []float64{4, 3, 2}, nil},
+		{"fixed copies top", 3, false, []string{"1 2 3 +"}, []float64{5, 1, 1}, nil},
+		{"large fixed", 6, false, []string{"1 2 3 4 5 6 7"}, []float64{7, 6, 5, 4, 3, 2}, nil},
+		{"dynamic grows", 0, false, []string{"1 2 3 4 5 6"}, []float64{6, 5, 4, 3, 2, 1}, nil},
+		{"dynamic shrinks", 0, false, []string{"1 2 3 + +"}, []float64{6}, nil},
+		{"dynamic empty", 0, false, []string{""}, []float64{}, nil},
+		{"dynamic uses zeros", 0, false, []string{"5 -"}, []float64{-5}, nil},
+		{"dynamic swap one value", 0, false, []string{"5 sw"}, []float64{0, 5}, nil},
+		{"dynamic store and restore", 0, false, []string{"5 rs1 rr1 rr1"}, []float64{5, 5, 5}, nil},
+		{"dynamic if", 0, false, []string{"5 1 if 2 then"}, []float64{2, 5}, nil},
+		{"dynamic if on empty", 0, false, []string{"if 2 else 3 then"}, []float64{3}, nil},
+		{"dynamic strict underflow", 0, true, []string{"5", "-"}, []float64{5}, errStackUnderflow},
+		{"dynamic undo", 0, false, []string{"1 2 3", "+", "undo"}, []float64{3, 2, 1}, nil},
+		{"dynamic rollback", 0, false, []string{"1 2", "3 + foo"}, []float64{2, 1}, errUnknownInput},
+		{"dynamic pick fills zeros", 0, false, []string{"1 pick3"}, []float64{0, 1, 0, 0, 0}, nil},
+		{"dynamic pick overflow", 0, false, []string{"1", "pick9223372036854775807"}, []float64{1}, errIndexOutOfRange},
+		{"dynamic roll overflow", 0, false, []string{"1 2", "roll9223372036854775807"}, []float64{2, 1}, errIndexOutOfRange},
+		{"dynamic pick too deep", 0, false, []string{"1", "pick100000000"}, []float64{1}, errIndexOutOfRange},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetStrict(c.strict)
+		if err := r.SetStackSize(c.size); err != nil {
+			t.Errorf("%q: Could not set stack size %v, got error %v", c.name, c.size, err)
+			continue
+		}
+
+		var err error
+		for _, l := range c.input {
+			err = r.Evaluate(l)
+		}
+
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if fmt.Sprintf("%v", values(c.stack...)) != fmt.Sprintf("%v", r.Stack()) {
+			t.Errorf("%q: Expected stack %v, but got %v", c.name, c.stack, r.Stack())
+		}
+
+		if c.size == 0 && r.Depth() != len(r.Stack()) {
+			t.Errorf("%q: Expected depth %v, but got %v", c.name, len(r.Stack()), r.Depth())
+		}
+	}
+}
+
+func TestSetStackSize(t *testing.T) {
+
+	r := New()
+
+	if err := r.SetStackSize(1); err != errValueNotAllowed {
+		t.Errorf("Expected error %v for stack size 1, but got %v", errValueNotAllowed, err)
+	}
+
+	if err := r.Evaluate("1 2 3"); err != nil {
+		t.Fatalf("Could not enter values, got error %v", err)
+	}
+
+	// Fixed to dynamic keeps the entered values
+	_ = r.SetStackSize(0)
+	if fmt.Sprintf("%v", r.Stack()) != "[3 2 1]" || r.Settings().StackSize != 0 {
+		t.Errorf("Expected dynamic stack [3 2 1], but got %v", r.Stack())
+	}
+
+	// Dynamic to smaller fixed drops the top values
+	_ = r.SetStackSize(2)
+	if fmt.Sprintf("%v", r.Stack()) != "[3 2]" || r.Depth() != 2 || r.Settings().StackSize != 2 {
+		t.Errorf("Expected fixed stack [3 2] with depth 2, but got %v with depth %v", r.Stack(), r.Depth())
+	}
+
+	// Fixed to larger fixed pads with zeros
+	_ = r.SetStackSize(5)
+	if fmt.Sprintf("%v", r.Stack()) != "[3 2 0 0 0]" || r.Depth() != 2 {
+		t.Errorf("Expected fixed stack [3 2 0 0 0] with depth 2, but got %v with depth %v", r.Stack(), r.Depth())
+	}
+
+	// Undo adapts to the current stack model
+	_ = r.SetStackSize(0)
+	if err := r.Evaluate("undo"); err != nil {
+		t.Fatalf("Could not undo, got error %v", err)
+	}
+	if fmt.Sprintf("%v", r.Stack()) != "[]" {
+		t.Errorf("Expected empty dynamic stack after undo, but got %v", r.Stack())
+	}
+
+	r.ClearStack()
+	if len(r.Stack()) != 0 || r.Val().Float64() != 0 {
+		t.Errorf("Expected empty dynamic stack after clear, but got %v", r.Stack())
+	}
+	r.ClearVal()
+}
+
+func TestStackOperators(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		size  int // zero for dynamic stack
+		input string
+		stack []float64
+		err   error
+	}{
+		{"dup", 4, "1 2 dup", []float64{2, 2, 1, 0}, nil},
+		{"drop", 4, "1 2 3 drop", []float64{2, 1, 0, 0}, nil},
+		{"over", 4, "1 2 over", []float64{1, 2, 1, 0}, nil},
+		{"rot", 4, "1 2 3 rot", []float64{1, 3, 2, 0}, nil},
+		{"-rot", 4, "1 2 3 -rot", []float64{2, 1, 3, 0}, nil},
+		{"rot and back", 4, "1 2 3 rot -rot", []float64{3, 2, 1, 0}, nil},
+		{"nip", 4, "1 2 3 nip", []float64{3, 1, 0, 0}, nil},
+		{"tuck", 4, "1 2 tuck", []float64{2, 1, 2, 0}, nil},
+		{"roll 0", 4, "1 2 3 0 roll", []float64{3, 2, 1, 1}, nil},
+		{"roll 1 is swap", 4, "1 2 3 1 roll", []float64{2, 3, 1, 1}, nil},
+		{"roll 2", 4, "1 2 3 4 2 roll", []float64{2, 4, 3, 2}, nil},
+		{"roll 3", 4, "1 2 3 4 roll3", []float64{1, 4, 3, 2}, nil},
+		{"roll outside fixed stack", 4, "1 2 3 4 roll", []float64{0, 0, 0, 0}, errIndexOutOfRange},
+		{"roll negative", 4, "1 -1 roll", []float64{0, 0, 0, 0}, errIndexOutOfRange},
+		{"roll fraction", 4, "1 0.5 roll", []float64{0, 0, 0, 0}, errIndexOutOfRange},
+		{"pick 0 is dup", 4, "1 2 0 pick", []float64{2, 2, 1, 0}, nil},
+		{"pick 2", 4, "1 2 3 2 pick", []float64{1, 3, 2, 1}, nil},
+		{"dynamic roll", 4, "1 2 3 roll2", []float64{1, 3, 2, 0}, nil},
+		{"dynamic pick", 4, "1 2 3 pick1", []float64{2, 3, 2, 1}, nil},
+		{"dynamic pick invalid", 4, "1 pickx", []float64{0, 0, 0, 0}, errIndexOutOfRange},
+		{"depth", 4, "5 6 depth", []float64{2, 6, 5, 0}, nil},
+		{"depth of empty", 4, "depth", []float64{0, 0, 0, 0}, nil},
+		{"clear", 4, "1 2 3 clear", []float64{0, 0, 0, 0}, nil},
+
+		// Dynamic stack
+		{"dynamic dup", 0, "1 dup", []float64{1, 1}, nil},
+		{"dynamic drop", 0, "1 2 drop", []float64{1}, nil},
+		{"dynamic drop empty", 0, "drop", []float64{}, nil},
+		{"dynamic over", 0, "1 2 over", []float64{1, 2, 1}, nil},
+		{"dynamic nip", 0, "1 2 nip", []float64{2}, nil},
+		{"dynamic deep roll", 0, "1 2 3 4 5 6 5 roll", []float64{1, 6, 5, 4, 3, 2}, nil},
+		{"dynamic deep pick", 0, "1 2 3 4 5 6 pick5", []float64{1, 6, 5, 4, 3, 2, 1}, nil},
+		{"dynamic pick below", 0, "1 pick2", []float64{0, 1, 0, 0}, nil},
+		{"dynamic depth", 0, "1 2 3 depth", []float64{3, 3, 2, 1}, nil},
+		{"dynamic clear", 0, "1 2 3 clear", []float64{}, nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+		if err := r.SetStackSize(c.size); err != nil {
+			t.Errorf("%q: Could not set stack size %v, got error %v", c.name, c.size, err)
+			continue
+		}
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if fmt.Sprintf("%v", values(c.stack...)) != fmt.Sprintf("%v", r.Stack()) {
+			t.Errorf("%q: Expected stack %v, but got %v", c.name, c.stack, r.Stack())
+		}
+	}
+}
+
+func TestStackOperatorsStrict(t *testing.T) {
+
+	cases := []struct {
+		input string
+		err   error
+	}{
+		{"1 dup", nil},
+		{"dup", errStackUnderflow},
+		{"1 over", errStackUnderflow},
+		{"1 2 rot", errStackUnderflow},
+		{"1 2 3 rot", nil},
+		{"1 2 2 roll", errStackUnderflow},
+		{"1 2 1 pick", nil},
+		{"1 pick1", errStackUnderflow},
+		{"drop", errStackUnderflow},
+		{"depth", nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetStrict(true)
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.input, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Package rpncalc statistics operations
+package rpncalc
+
+import "math"
+
+// Stats holds the statistics summation registers
+type Stats struct {
+	N     int     `json:"n"`
+	SumX  float64 `json:"sumx"`
+	SumX2 float64 `json:"sumx2"`
+	SumY  float64 `json:"sumy"`
+	SumY2 float64 `json:"sumy2"`
+	SumXY float64 `json:"sumxy"`
+}
+
+// Stats returns the statistics summation registers
+func (r *RpnCalc) Stats() Stats {
+	return r.stats
+}
+
+// ClearStats clears the statistics summation registers
+func (r *RpnCalc) ClearStats() {
+	r.stats = Stats{}
+}
+
+// accumulate adds, or with sign -1 removes, the pair x, y
+func (s *Stats) accumulate(x, y float64, sign int) {
+	f := float64(sign)
+	s.N += sign
+	s.SumX += f * x
+	s.SumX2 += f * x * x
+	s.SumY += f * y
+	s.SumY2 += f * y * y
+	s.SumXY += f * x * y
+}
+
+// sdev returns the standard deviation of x and y, sample or population
+func (s Stats) sdev(sample bool) (float64, float64, error) {
+	n := float64(s.N)
+	d := n
+	if sample {
+		d = n - 1
+	}
+	if d < 1 {
+		return 0.0, 0.0, errNoStatistics
+	}
+
+	v := func(sum, sum2 float64) float64 {
+		// rounding may give a tiny negative variance
+		return math.Sqrt(math.Max(0, (sum2-sum*sum/n)/d))
+	}
+
+	return v(s.SumX, s.SumX2), v(s.SumY, s.SumY2), nil
+}
+
+// line returns the slope and intercept of the least squares line y = a + bx
+func (s Stats) line() (float64, float64, error) {
+	if s.N < 2 {
+		return 0.0, 0.0, errNoStatistics
+	}
+
+	n := float64(s.N)
+	sxx := n*s.SumX2 - s.SumX*s.SumX
+	if sxx == 0 {
+		return 0.0, 0.0, errDivisionByZero
+	}
+	b := (n*s.SumXY - s.SumX*s.SumY) / sxx
+
+	return b, (s.SumY - b*s.SumX) / n, nil
+}
+
+// pushPair pushes y and then x, leaving x in pos 0 and y in pos 1
+func (r *RpnCalc) pushPair(x, y float64) error {
+	vx, err := r.value(x)
+	if err != nil {
+		return err
+	}
+	vy, err := r.value(y)
+	if err != nil {
+		return err
+	}
+
+	r.push(vy)
+	r.push(vx)
+	return nil
+}
+
+// pushFloat pushes a float64 result converted to the current mode
+func (r *RpnCalc) pushFloat(f float64) error {
+	v, err := r.value(f)
+	if err != nil {
+		return err
+	}
+
+	r.push(v)
+	return nil
+}
+
+// statAccumulate reads x from pos 0 and y from pos 1 and replaces x
+// with the number of accumulated values
+func (r *RpnCalc) statAccumulate(sign int) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+	if err := r.noUnits(2); err != nil {
+		return err
+	}
+	if err := r.noTimes(2); err != nil {
+		return err
+	}
+	if sign < 0 && r.stats.N < 1 {
+		return errNoStatistics
+	}
+
+	x, y := r.stack[0].Float64(), 0.0
+	if len(r.stack) > 1 {
+		y = r.stack[1].Float64()
+	}
+
+	s := r.stats
+	s.accumulate(x, y, sign)
+	v, err := r.value(float64(s.N))
+	if err != nil {
+		return err
+	}
+
+	r.stats = s
+	r.setX(v)
+	return nil
+}
+
+func opStatAdd(r *RpnCalc, _ string) error {
+	return r.statAccumulate(1)
+}
+
+func opStatRemove(r *RpnCalc, _ string) error {
+	return r.statAccumulate(-1)
+}
+
+func opStatClear(r *RpnCalc, _ string) error {
+	r.ClearStats()
+	return nil
+}
+
+func opStatN(r *RpnCalc, _ string) error {
+	return r.pushFloat(float64(r.stats.N))
+}
+
+func opStatSum(r *RpnCalc, _ string) error {
+	return r.pushPair(r.stats.SumX, r.stats.SumY)
+}
+
+func opStatMean(r *RpnCalc, _ string) error {
+	if r.stats.N < 1 {
+		return errNoStatistics
+	}
+
+	n := float64(r.stats.N)
+	return r.pushPair(r.stats.SumX/n, r.stats.SumY/n)
+}
+
+func opStatSampleDev(r *RpnCalc, _ string) error {
+	sx, sy, err := r.stats.sdev(true)
+	if err != nil {
+		return err
+	}
+
+	return r.pushPair(sx, sy)
+}
+
+func opStatPopulationDev(r *RpnCalc, _ string) error {
+	sx, sy, err := r.stats.sdev(false)
+	if err != nil {
+		return err
+	}
+
+	return r.pushPair(sx, sy)
+}
+
+func opStatSlope(r *RpnCalc, _ string) error {
+	b, _, err := r.stats.line()
+	if err != nil {
+		return err
+	}
+
+	return r.pushFloat(b)
+}
+
+func opStatIntercept(r *RpnCalc, _ string) error {
+	_, a, err := r.stats.line()
+	if err != nil {
+		return err
+	}
+
+	return r.pushFloat(a)
+}
+
+func opStatCorrelation(r *RpnCalc, _ string) error {
+	s := r.stats
+	if s.N < 2 {
+		return errNoStatistics
+	}
+
+	n := float64(s.N)
+	sxx := n*s.SumX2 - s.SumX*s.SumX
+	syy := n*s.SumY2 - s.SumY*s.SumY
+	if sxx <= 0 || syy <= 0 {
+		return errDivisionByZero
+	}
+
+	return r.pushFloat((n*s.SumXY - s.SumX*s.SumY) / math.Sqrt(sxx*syy))
+}
+
+func opStatEstimateY(r *RpnCalc, _ string) error {
+	b, a, err := r.stats.line()
+	if err != nil {
+		return err
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return a + b*x, nil
+	})
+}
+
+func opStatEstimateX(r *RpnCalc, _ string) error {
+	b, a, err := r.stats.line()
+	if err != nil {
+		return err
+	}
+	if b == 0 {
+		return errDivisionByZero
+	}
+
+	return r.unaryOp(func(y float64, _ string) (float64, error) {
+		return (y - a) / b, nil
+	})
+}
@@ -0,0 +1,76 @@
+package rpncalc
+
+import "testing"
+
+func TestStatisticsOps(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   []float64
+		err   error
+	}{
+		{"accumulate replaces x with n", "7 2 s+", []float64{1, 7, 0, 0}, nil},
+		{"sigma alias", "0 2 Σ+ 0 3 Σ+", []float64{2, 0, 1, 0}, nil},
+		{"n", "0 2 s+ 0 4 s+ clear sn", []float64{2, 0, 0, 0}, nil},
+		{"sum", "1 2 s+ clear 3 4 s+ clear ssum", []float64{6, 4, 0, 0}, nil},
+		{"mean", "1 2 s+ clear 3 4 s+ clear smean", []float64{3, 2, 0, 0}, nil},
+		{"mean without data fails", "smean", nil, errNoStatistics},
+		{"remove", "1 2 s+ clear 3 4 s+ clear 3 4 s- clear smean", []float64{2, 1, 0, 0}, nil},
+		{"remove without data fails", "1 s-", nil, errNoStatistics},
+		{"sample sdev", "2 s+ clear 4 s+ clear 4 s+ clear 4 s+ clear 5 s+ clear 5 s+ clear 7 s+ clear 9 s+ clear sdev", []float64{2.138089935299395, 0, 0, 0}, nil},
+		{"population sdev", "2 s+ clear 4 s+ clear 4 s+ clear 4 s+ clear 5 s+ clear 5 s+ clear 7 s+ clear 9 s+ clear psdev", []float64{2, 0, 0, 0}, nil},
+		{"sample sdev needs two values", "2 s+ ssdev", nil, errNoStatistics},
+		{"sample sdev alias", "2 s+ clear 4 s+ clear ssdev", []float64{1.4142135623730951, 0, 0, 0}, nil},
+		{"slope", "3 1 s+ clear 5 2 s+ clear 7 3 s+ clear slope", []float64{2, 0, 0, 0}, nil},
+		{"intercept", "3 1 s+ clear 5 2 s+ clear 7 3 s+ clear intercept", []float64{1, 0, 0, 0}, nil},
+		{"correlation", "3 1 s+ clear 5 2 s+ clear 7 3 s+ clear corr", []float64{1, 0, 0, 0}, nil},
+		{"negative correlation", "3 1 s+ clear 1 2 s+ clear corr", []float64{-1, 0, 0, 0}, nil},
+		{"estimate y", "3 1 s+ clear 5 2 s+ clear 10 yhat", []float64{21, 0, 0, 0}, nil},
+		{"estimate x", "3 1 s+ clear 5 2 s+ clear 21 xhat", []float64{10, 0, 0, 0}, nil},
+		{"slope of vertical line fails", "3 1 s+ clear 5 1 s+ clear slope", nil, errDivisionByZero},
+		{"clear statistics", "1 s+ sclear smean", nil, errNoStatistics},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		for i, v := range r.Stack() {
+			if !almostEqual(v.Float64(), c.exp[i]) {
+				t.Errorf("%q: Expected stack %v, but got %v", c.name, c.exp, r.Stack())
+				break
+			}
+		}
+	}
+}
+
+func TestStatisticsUndo(t *testing.T) {
+	r := New()
+
+	for _, l := range []string{"1 s+", "2 s+", "undo"} {
+		if err := r.Evaluate(l); err != nil {
+			t.Fatalf("Unexpected error %v for %q", err, l)
+		}
+	}
+
+	if n := r.Stats().N; n != 1 {
+		t.Errorf("Expected n to be 1 after undo, but got %v", n)
+	}
+
+	if err := r.Evaluate("3 s+ 0 0 /"); err == nil {
+		t.Fatalf("Expected division by zero")
+	}
+
+	if n := r.Stats().N; n != 1 {
+		t.Errorf("Expected n to be 1 after failed input, but got %v", n)
+	}
+}
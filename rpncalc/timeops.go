@@ -0,0 +1,354 @@
+// Package rpncalc dates, times and durations
+package rpncalc
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// embedded time zone database, time zones work without a system database
+	_ "time/tzdata"
+)
+
+// now returns the current time, replaceable in tests
+var now = time.Now
+
+// dateLayouts are the accepted ISO 8601 date and date time literals
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+var (
+	timeOfDay    = regexp.MustCompile(`^\d{1,2}:\d{2}(:\d{2}(\.\d+)?)?$`)
+	durationPart = regexp.MustCompile(`^(\d+(\.\d+)?)(d|h|ms|us|ns|m|s)`)
+)
+
+// durationUnits are the units of duration literal parts, a duration gets
+// the unit of its largest part
+var durationUnits = map[string]string{
+	"d":  "day",
+	"h":  "hr",
+	"m":  "minute",
+	"s":  "s",
+	"ms": "ms",
+	"us": "us",
+	"ns": "s",
+}
+
+var durationSeconds = map[string]float64{
+	"d":  86400,
+	"h":  3600,
+	"m":  60,
+	"s":  1,
+	"ms": 1e-3,
+	"us": 1e-6,
+	"ns": 1e-9,
+}
+
+// SetZone sets the time zone used for date literals and new dates
+func (r *RpnCalc) SetZone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return errUnknownZone
+	}
+	r.zone = loc
+	return nil
+}
+
+// parseTime parses dates, times of day and durations, returning false if
+// the token is none of them
+func (r *RpnCalc) parseTime(t string) (Value, bool) {
+	for _, l := range dateLayouts {
+		if d, err := time.ParseInLocation(l, t, r.zone); err == nil {
+			return NewTime(d), true
+		}
+	}
+
+	if timeOfDay.MatchString(t) {
+		layout := "15:04"
+		if strings.Count(t, ":") == 2 {
+			layout = "15:04:05"
+		}
+		c, err := time.Parse(layout, t)
+		if err != nil {
+			return Value{}, false
+		}
+		y, m, d := now().In(r.zone).Date()
+		return NewTime(time.Date(y, m, d, c.Hour(), c.Minute(), c.Second(), c.Nanosecond(), r.zone)), true
+	}
+
+	return r.parseDuration(t)
+}
+
+// parseDuration parses durations like 1h30m, 2d or -90s to a value with
+// the unit of the largest part
+func (r *RpnCalc) parseDuration(t string) (Value, bool) {
+	sign := 1.0
+	if strings.HasPrefix(t, "-") {
+		sign, t = -1.0, t[1:]
+	}
+	if t == "" {
+		return Value{}, false
+	}
+
+	seconds, largest := 0.0, ""
+	for t != "" {
+		m := durationPart.FindStringSubmatch(t)
+		if m == nil {
+			return Value{}, false
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		seconds += n * durationSeconds[m[3]]
+		if largest == "" || durationSeconds[m[3]] > durationSeconds[largest] {
+			largest = m[3]
+		}
+		t = t[len(m[0]):]
+	}
+
+	u := findUnit(durationUnits[largest])
+	v, err := convert(NewFloat(sign*seconds), findUnit("s"), u)
+	if err != nil {
+		return Value{}, false
+	}
+	return v.withUnit(u), true
+}
+
+// timeArgs returns true if any of the first n values on the stack is a
+// date
+func (r *RpnCalc) timeArgs(n int) bool {
+	for i := 0; i < n && i < len(r.stack); i++ {
+		if r.stack[i].IsTime() {
+			return true
+		}
+	}
+	return false
+}
+
+// noTimes returns errTime if any of the first n values is a date
+func (r *RpnCalc) noTimes(n int) error {
+	if r.timeArgs(n) {
+		return errTime
+	}
+	return nil
+}
+
+// seconds returns a duration value in seconds
+func seconds(v Value) (float64, error) {
+	if v.IsTime() || v.IsComplex() {
+		return 0, errDimensionMismatch
+	}
+	s, err := convert(v, v.u, findUnit("s"))
+	if err != nil {
+		return 0, err
+	}
+	return s.Float64(), nil
+}
+
+// addSeconds adds a number of seconds to a date
+func addSeconds(t time.Time, s float64) (time.Time, error) {
+	if math.IsNaN(s) || math.Abs(s) > float64(math.MaxInt64)/1e9 {
+		return time.Time{}, errOverflow
+	}
+	return t.Add(time.Duration(math.Round(s * 1e9))), nil
+}
+
+// durationValue returns a number of seconds in the largest of the units
+// day, hr, minute and s that is not larger than the duration
+func durationValue(s float64) Value {
+	u := findUnit("s")
+	for _, n := range []string{"day", "hr", "minute"} {
+		f, _ := findUnit(n).Factor.Float64()
+		if math.Abs(s) >= f {
+			u = findUnit(n)
+			break
+		}
+	}
+
+	f, _ := u.Factor.Float64()
+	return NewFloat(s / f).withUnit(u)
+}
+
+// opTimeAdd adds a duration to a date, in any order
+func opTimeAdd(r *RpnCalc, _ string) error {
+	t, d := r.stack[1], r.stack[0]
+	if !t.IsTime() {
+		t, d = d, t
+	}
+
+	s, err := seconds(d)
+	if err != nil {
+		return err
+	}
+	z, err := addSeconds(t.Time(), s)
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(NewTime(z))
+	return nil
+}
+
+// opTimeSubtract subtracts a duration from a date, or gives the duration
+// between two dates
+func opTimeSubtract(r *RpnCalc, _ string) error {
+	y, x := r.stack[1], r.stack[0]
+	if !y.IsTime() {
+		return errDimensionMismatch
+	}
+
+	if x.IsTime() {
+		a, b := y.Time(), x.Time()
+		s := float64(a.Unix()-b.Unix()) + float64(a.Nanosecond()-b.Nanosecond())/1e9
+		r.pop()
+		r.setX(durationValue(s))
+		return nil
+	}
+
+	s, err := seconds(x)
+	if err != nil {
+		return err
+	}
+	z, err := addSeconds(y.Time(), -s)
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(NewTime(z))
+	return nil
+}
+
+// opTimeDifference gives the duration between two dates, regardless of
+// their order
+func opTimeDifference(r *RpnCalc, t string) error {
+	if !r.stack[1].IsTime() || !r.stack[0].IsTime() {
+		return errTime
+	}
+	if err := opTimeSubtract(r, t); err != nil {
+		return err
+	}
+
+	x := r.stack[0]
+	if x.Float64() < 0 {
+		r.setX(NewFloat(-x.Float64()).withUnit(x.u))
+	}
+	return nil
+}
+
+// timeCompare compares two dates
+func (r *RpnCalc) timeCompare(f func(int) bool) error {
+	y, x := r.stack[1], r.stack[0]
+	if !y.IsTime() || !x.IsTime() {
+		return errDimensionMismatch
+	}
+
+	c := 0
+	switch {
+	case y.Time().Before(x.Time()):
+		c = -1
+	case y.Time().After(x.Time()):
+		c = 1
+	}
+
+	v, err := r.value(boolVal(f(c)))
+	if err != nil {
+		return err
+	}
+
+	r.pop()
+	r.setX(v)
+	return nil
+}
+
+// convertZone shows the date x in the time zone with the name
+func (r *RpnCalc) convertZone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return errUnknownZone
+	}
+	if err := r.need(1); err != nil {
+		return err
+	}
+
+	x := r.stack[0]
+	if !x.IsTime() {
+		return errTime
+	}
+
+	r.setX(NewTime(x.Time().In(loc)))
+	return nil
+}
+
+func opNow(r *RpnCalc, _ string) error {
+	r.push(NewTime(now().In(r.zone)))
+	return nil
+}
+
+func opToday(r *RpnCalc, _ string) error {
+	y, m, d := now().In(r.zone).Date()
+	r.push(NewTime(time.Date(y, m, d, 0, 0, 0, 0, r.zone)))
+	return nil
+}
+
+// opUnixToDate converts a Unix timestamp in seconds to a date
+func opUnixToDate(r *RpnCalc, _ string) error {
+	x := r.stack[0]
+	if x.IsTime() {
+		return nil
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noUnits(1); err != nil {
+		return err
+	}
+
+	s := x.Float64()
+	z, err := addSeconds(time.Unix(0, 0), s)
+	if err != nil {
+		return err
+	}
+
+	r.setX(NewTime(z.In(r.zone)))
+	return nil
+}
+
+// opDateToUnix converts a date to a Unix timestamp in seconds
+func opDateToUnix(r *RpnCalc, _ string) error {
+	x := r.stack[0]
+	if !x.IsTime() {
+		return errTime
+	}
+
+	t := x.Time()
+	v, err := r.value(float64(t.Unix()) + float64(t.Nanosecond())/1e9)
+	if err != nil {
+		return err
+	}
+
+	r.setX(v)
+	return nil
+}
+
+func opToDays(r *RpnCalc, _ string) error {
+	return r.convertTo("day")
+}
+
+func opToHours(r *RpnCalc, _ string) error {
+	return r.convertTo("hr")
+}
+
+func opToMinutes(r *RpnCalc, _ string) error {
+	return r.convertTo("minute")
+}
+
+func opToSeconds(r *RpnCalc, _ string) error {
+	return r.convertTo("s")
+}
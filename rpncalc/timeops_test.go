@@ -0,0 +1,103 @@
+package rpncalc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimes(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string
+		err   error
+	}{
+		{"date literal", "2024-03-01", "2024-03-01T00:00:00Z", nil},
+		{"date time literal", "2024-03-01T14:30", "2024-03-01T14:30:00Z", nil},
+		{"date time with zone", "2024-03-01T14:30:00+01:00", "2024-03-01T14:30:00+01:00", nil},
+		{"time of day", "14:30", "2024-03-01T14:30:00Z", nil},
+		{"today", "today", "2024-03-01T00:00:00Z", nil},
+		{"now", "now", "2024-03-01T10:20:30Z", nil},
+		{"duration literal", "1h30m", "1.5 hr", nil},
+		{"duration in days", "2d12h", "2.5 day", nil},
+		{"negative duration", "-90s", "-90 s", nil},
+		{"add duration", "2024-03-01 1h30m +", "2024-03-01T01:30:00Z", nil},
+		{"add date to duration", "2d 2024-03-01 +", "2024-03-03T00:00:00Z", nil},
+		{"add time unit", "2024-03-01 3 day +", "2024-03-04T00:00:00Z", nil},
+		{"subtract duration", "2024-03-01 1d -", "2024-02-29T00:00:00Z", nil},
+		{"subtract dates", "2024-03-01 2024-02-01 -", "29 day", nil},
+		{"subtract close dates", "12:30 12:00 -", "30 minute", nil},
+		{"negative difference", "2024-02-01 2024-03-01 -", "-29 day", nil},
+		{"difference", "2024-02-01 2024-03-01 diff", "29 day", nil},
+		{"difference to hours", "2024-03-01 2024-02-29 diff hours", "24 hr", nil},
+		{"duration to minutes", "1h30m minutes", "90 minute", nil},
+		{"duration to seconds", "2m seconds", "120 s", nil},
+		{"duration to days", "36h days", "1.5 day", nil},
+		{"compare dates", "2024-02-01 2024-03-01 <", "1", nil},
+		{"unix timestamp to date", "86400 date", "1970-01-02T00:00:00Z", nil},
+		{"date to unix timestamp", "1970-01-02 unix", "86400", nil},
+		{"time zone", "2024-03-01T12:00Z tz Asia/Tokyo", "2024-03-01T21:00:00+09:00", nil},
+		{"unknown time zone", "now tz Nowhere/Nothing", "", errUnknownZone},
+		{"time zone of number", "1 tz UTC", "", errTime},
+		{"add unitless", "now 5 +", "", errDimensionMismatch},
+		{"add dates", "now now +", "", errDimensionMismatch},
+		{"subtract date from duration", "1h now -", "", errDimensionMismatch},
+		{"other operations fail", "now sqrt", "", errTime},
+		{"multiply fails", "now 2 *", "", errTime},
+		{"bitwise fails", "now 1 and", "", errNotInteger},
+		{"unit fails", "now m", "", errTime},
+		{"overflow", "now 1e300 s +", "", errOverflow},
+	}
+
+	defer func(f func() time.Time) { now = f }(now)
+	now = func() time.Time {
+		return time.Date(2024, 3, 1, 10, 20, 30, 0, time.UTC)
+	}
+
+	for _, c := range cases {
+		r := New()
+		if err := r.SetZone("UTC"); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if s := r.Val().String(); s != c.exp {
+			t.Errorf("%q: Expected %q, but got %q", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestTimeText(t *testing.T) {
+	r := New()
+
+	if err := r.SetZone("Europe/Stockholm"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if err := r.SetZone("Nowhere/Nothing"); err != errUnknownZone {
+		t.Errorf("Expected error %v, but got %v", errUnknownZone, err)
+	}
+	if z := r.Settings().Zone; z != "Europe/Stockholm" {
+		t.Errorf("Expected zone %q, but got %q", "Europe/Stockholm", z)
+	}
+
+	if err := r.Evaluate("2024-07-01T12:00"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if s := r.Format(r.Val(), 2); s != "2024-07-01 12:00:00 CEST" {
+		t.Errorf("Expected format %q, but got %q", "2024-07-01 12:00:00 CEST", s)
+	}
+
+	r.SetBase(Hexadecimal)
+	if s := r.Format(r.Val(), 2); s != "2024-07-01 12:00:00 CEST" {
+		t.Errorf("Expected date in hex mode %q, but got %q", "2024-07-01 12:00:00 CEST", s)
+	}
+}
@@ -0,0 +1,200 @@
+// Package rpncalc trigonometric operators
+package rpncalc
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// AngleMode defines the unit of angles used by the trigonometric operators
+type AngleMode int
+
+const (
+	// Radians is the default angle mode
+	Radians AngleMode = iota
+	// Degrees is 360 for a full turn
+	Degrees
+	// Gradians is 400 for a full turn
+	Gradians
+)
+
+var angleModeNames = []string{"rad", "deg", "grad"}
+
+// String returns the short name of the angle mode
+func (m AngleMode) String() string {
+	if m < 0 || int(m) >= len(angleModeNames) {
+		return fmt.Sprintf("AngleMode(%d)", int(m))
+	}
+	return angleModeNames[m]
+}
+
+// MarshalText makes the angle mode show up with its name in JSON
+func (m AngleMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// ParseAngleMode returns the angle mode with the short name s
+func ParseAngleMode(s string) (AngleMode, error) {
+	for i, n := range angleModeNames {
+		if n == s {
+			return AngleMode(i), nil
+		}
+	}
+	return Radians, errValueNotAllowed
+}
+
+// fullTurn returns the size of a full turn in the angle mode
+func (m AngleMode) fullTurn() float64 {
+	switch m {
+	case Degrees:
+		return 360
+	case Gradians:
+		return 400
+	}
+	return 2 * math.Pi
+}
+
+func (r *RpnCalc) toRadians(x float64) float64 {
+	return x / r.angle.fullTurn() * 2 * math.Pi
+}
+
+func (r *RpnCalc) fromRadians(x float64) float64 {
+	return x / (2 * math.Pi) * r.angle.fullTurn()
+}
+
+// quarterTurns returns the number of quarter turns, modulo 4, if x is an
+// exact multiple of a quarter turn in degrees or gradians
+func (r *RpnCalc) quarterTurns(x float64) (int, bool) {
+	if r.angle == Radians {
+		return 0, false
+	}
+	q := x / (r.angle.fullTurn() / 4)
+	if q != math.Trunc(q) || math.Abs(q) > 1<<52 {
+		return 0, false
+	}
+	return int(math.Mod(math.Mod(q, 4)+4, 4)), true
+}
+
+func opSin(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return cmplx.Sin(r.toComplexRadians(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if q, ok := r.quarterTurns(x); ok {
+			return []float64{0, 1, 0, -1}[q], nil
+		}
+		return math.Sin(r.toRadians(x)), nil
+	})
+}
+
+func opCos(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return cmplx.Cos(r.toComplexRadians(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if q, ok := r.quarterTurns(x); ok {
+			return []float64{1, 0, -1, 0}[q], nil
+		}
+		return math.Cos(r.toRadians(x)), nil
+	})
+}
+
+func opTan(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return cmplx.Tan(r.toComplexRadians(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if q, ok := r.quarterTurns(x); ok {
+			if q%2 == 1 {
+				return 0.0, errDomain
+			}
+			return 0.0, nil
+		}
+		return math.Tan(r.toRadians(x)), nil
+	})
+}
+
+func opArcSin(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, outsideUnit) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return r.fromComplexRadians(cmplx.Asin(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x < -1 || x > 1 {
+			return 0.0, errDomain
+		}
+		return r.fromRadians(math.Asin(x)), nil
+	})
+}
+
+func opArcCos(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, outsideUnit) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return r.fromComplexRadians(cmplx.Acos(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		if x < -1 || x > 1 {
+			return 0.0, errDomain
+		}
+		return r.fromRadians(math.Acos(x)), nil
+	})
+}
+
+func opArcTan(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return r.fromComplexRadians(cmplx.Atan(x)), nil
+		})
+	}
+
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return r.fromRadians(math.Atan(x)), nil
+	})
+}
+
+func opArcTan2(r *RpnCalc, _ string) error {
+	return r.binaryOp(func(y, x float64) (float64, error) {
+		return r.fromRadians(math.Atan2(y, x)), nil
+	})
+}
+
+func opDegToRad(r *RpnCalc, _ string) error {
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return x / 180 * math.Pi, nil
+	})
+}
+
+func opRadToDeg(r *RpnCalc, _ string) error {
+	return r.unaryOp(func(x float64, _ string) (float64, error) {
+		return x / math.Pi * 180, nil
+	})
+}
+
+func opDegreesMode(r *RpnCalc, _ string) error {
+	r.SetAngleMode(Degrees)
+	return nil
+}
+
+func opRadiansMode(r *RpnCalc, _ string) error {
+	r.SetAngleMode(Radians)
+	return nil
+}
+
+func opGradiansMode(r *RpnCalc, _ string) error {
+	r.SetAngleMode(Gradians)
+	return nil
+}
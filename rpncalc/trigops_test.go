@@ -0,0 +1,86 @@
+package rpncalc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTrigonometry(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		mode  AngleMode
+		input string
+		exp   float64
+		err   error
+	}{
+		{"sin rad", Radians, "pi 2 / sin", 1, nil},
+		{"sin deg", Degrees, "30 sin", 0.5, nil},
+		{"sin 180 deg is exact", Degrees, "180 sin", 0, nil},
+		{"sin -90 deg", Degrees, "-90 sin", -1, nil},
+		{"sin grad", Gradians, "100 sin", 1, nil},
+		{"cos rad", Radians, "0 cos", 1, nil},
+		{"cos deg", Degrees, "60 cos", 0.5000000000000001, nil},
+		{"cos 90 deg is exact", Degrees, "90 cos", 0, nil},
+		{"cos 540 deg", Degrees, "540 cos", -1, nil},
+		{"tan deg", Degrees, "45 tan", 0.9999999999999999, nil},
+		{"tan 180 deg", Degrees, "180 tan", 0, nil},
+		{"tan 90 deg fails", Degrees, "90 tan", 0, errDomain},
+		{"asin deg", Degrees, "0.5 asin", 30.000000000000004, nil},
+		{"asin out of range", Degrees, "2 asin", 0, errDomain},
+		{"acos rad", Radians, "-1 acos", math.Pi, nil},
+		{"acos out of range", Radians, "-1.5 acos", 0, errDomain},
+		{"atan grad", Gradians, "1 atan", 50, nil},
+		{"atan2 deg", Degrees, "1 -1 atan2", 135, nil},
+		{"atan2 rad", Radians, "-1 0 atan2", -math.Pi / 2, nil},
+		{"deg to rad", Radians, "180 deg>rad", math.Pi, nil},
+		{"rad to deg", Degrees, "pi 2 / rad>deg", 90, nil},
+		{"switch mode", Radians, "deg 90 sin", 1, nil},
+		{"switch mode grad", Degrees, "grad 200 cos", -1, nil},
+		{"switch mode rad", Degrees, "rad pi cos", -1, nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetAngleMode(c.mode)
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if c.err == nil && !almostEqual(r.Val().Float64(), c.exp) {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, r.Val())
+		}
+	}
+}
+
+func TestAngleMode(t *testing.T) {
+
+	for _, m := range []AngleMode{Radians, Degrees, Gradians} {
+		p, err := ParseAngleMode(m.String())
+		if err != nil || p != m {
+			t.Errorf("Expected to parse angle mode %v, but got %v and error %v", m, p, err)
+		}
+	}
+
+	if _, err := ParseAngleMode("turns"); err != errValueNotAllowed {
+		t.Errorf("Expected error %v for unknown angle mode, but got %v", errValueNotAllowed, err)
+	}
+
+	r := New()
+	if err := r.Evaluate("deg"); err != nil {
+		t.Fatalf("Could not switch angle mode, got error %v", err)
+	}
+	if r.Settings().Angle != Degrees {
+		t.Errorf("Expected angle mode %v, but got %v", Degrees, r.Settings().Angle)
+	}
+
+	if err := r.Evaluate("undo"); err != nil {
+		t.Fatalf("Could not undo, got error %v", err)
+	}
+	if r.Settings().Angle != Radians {
+		t.Errorf("Expected angle mode %v after undo, but got %v", Radians, r.Settings().Angle)
+	}
+}
@@ -0,0 +1,487 @@
+// Package rpncalc financial time value of money operations
+package rpncalc
+
+import "math"
+
+// TVM holds the time value of money registers. The interest rate I is in
+// percent per period. Money received is positive and money paid negative.
+type TVM struct {
+	N     float64 `json:"n"`
+	I     float64 `json:"i"`
+	PV    float64 `json:"pv"`
+	PMT   float64 `json:"pmt"`
+	FV    float64 `json:"fv"`
+	Begin bool    `json:"begin"` // payments at the beginning of the periods
+}
+
+// Amortization holds the interest, principal and remaining balance of one
+// period of an amortization schedule
+type Amortization struct {
+	Period    int
+	Interest  float64
+	Principal float64
+	Balance   float64
+}
+
+const (
+	maxSolveSteps = 200
+	solveEpsilon  = 1e-12
+
+	// maxCashFlows is the largest number of cash flows of npv and irr
+	maxCashFlows = 1000
+)
+
+// TVM returns the time value of money registers
+func (r *RpnCalc) TVM() TVM {
+	return r.tvm
+}
+
+// ClearTVM clears the time value of money registers, keeping the payment
+// mode
+func (r *RpnCalc) ClearTVM() {
+	r.tvm = TVM{Begin: r.tvm.Begin}
+}
+
+// tvmReg returns the time value of money register with the name, or nil
+func (r *RpnCalc) tvmReg(name string) *float64 {
+	switch name {
+	case "n":
+		return &r.tvm.N
+	case "i":
+		return &r.tvm.I
+	case "pv":
+		return &r.tvm.PV
+	case "pmt":
+		return &r.tvm.PMT
+	case "fv":
+		return &r.tvm.FV
+	}
+	return nil
+}
+
+// realArgs returns an error if any of the first n values is not a plain
+// real number
+func (r *RpnCalc) realArgs(n int) error {
+	if err := r.notComplex(n); err != nil {
+		return err
+	}
+	if err := r.noUnits(n); err != nil {
+		return err
+	}
+	return r.noTimes(n)
+}
+
+// growth returns the compound growth (1+i)**n and the annuity factor
+// ((1+i)**n-1)/i, adjusted for payments at the beginning of the periods
+func (t TVM) growth(i, n float64) (float64, float64) {
+	g := math.Pow(1+i, n)
+	if math.Abs(i) < solveEpsilon {
+		return g, n
+	}
+
+	a := (g - 1) / i
+	if t.Begin {
+		a *= 1 + i
+	}
+	return g, a
+}
+
+// balance returns the sum of the present values of all cash flows, zero
+// when the registers are in balance
+func (t TVM) balance(i float64) float64 {
+	g, a := t.growth(i, t.N)
+	return t.PV + (t.PMT*a+t.FV)/g
+}
+
+// solve solves the register with the name using the other registers
+func (t TVM) solve(name string) (float64, error) {
+	i := t.I / 100
+	if i <= -1 {
+		return 0, errDomain
+	}
+
+	g, a := t.growth(i, t.N)
+	switch name {
+	case "fv":
+		return -(t.PV*g + t.PMT*a), nil
+	case "pv":
+		return -(t.PMT*a + t.FV) / g, nil
+	case "pmt":
+		if a == 0 {
+			return 0, errDivisionByZero
+		}
+		return -(t.PV*g + t.FV) / a, nil
+	case "n":
+		return t.solveN(i)
+	case "i":
+		z, err := solveRate(t.balance)
+		return z * 100, err
+	}
+	return 0, errInvalidRegister
+}
+
+// solveN solves the number of periods for the interest rate i
+func (t TVM) solveN(i float64) (float64, error) {
+	if math.Abs(i) < solveEpsilon {
+		if t.PMT == 0 {
+			return 0, errDivisionByZero
+		}
+		return -(t.PV + t.FV) / t.PMT, nil
+	}
+
+	// pv*g + p*(g-1) + fv = 0 gives g = (p-fv)/(pv+p)
+	p := t.PMT / i
+	if t.Begin {
+		p *= 1 + i
+	}
+	g := (p - t.FV) / (t.PV + p)
+	if g <= 0 || math.IsInf(g, 0) || math.IsNaN(g) {
+		return 0, errNoSolution
+	}
+
+	n := math.Log(g) / math.Log1p(i)
+	if n < 0 {
+		return 0, errNoSolution
+	}
+	return n, nil
+}
+
+// rateGrid are the rates searched for a sign change of a rate function,
+// positive rates first
+var rateGrid = [][]float64{
+	{0, 0.01, 0.05, 0.1, 0.2, 0.5, 1, 2, 5, 10, 100},
+	{0, -0.01, -0.05, -0.1, -0.2, -0.5, -0.9, -0.99, -0.9999},
+}
+
+// solveRate finds the rate, larger than -1, where f is zero. The first
+// sign change in the rate grid is narrowed down by bisection. A function
+// that is zero at both ends of an interval, like the balance of empty
+// registers, has no single root there.
+func solveRate(f func(float64) float64) (float64, error) {
+	for _, rates := range rateGrid {
+		for k := 1; k < len(rates); k++ {
+			lo, hi := rates[k-1], rates[k]
+			flo, fhi := f(lo), f(hi)
+			if flo == 0 && fhi != 0 {
+				return lo, nil
+			}
+			if flo == 0 || math.IsNaN(flo) || math.IsNaN(fhi) || (flo < 0) == (fhi < 0) {
+				continue
+			}
+			return bisect(f, lo, hi, flo), nil
+		}
+	}
+	return 0, errNoSolution
+}
+
+// bisect narrows down the root of f between lo and hi, where f(lo) is flo
+func bisect(f func(float64) float64, lo, hi, flo float64) float64 {
+	for k := 0; k < maxSolveSteps; k++ {
+		mid := (lo + hi) / 2
+		if mid == lo || mid == hi {
+			break
+		}
+		fmid := f(mid)
+		if fmid == 0 {
+			return mid
+		}
+		if (fmid < 0) == (flo < 0) {
+			lo, flo = mid, fmid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Amortize returns the amortization schedule for the periods from and to,
+// counted from 1, of the loan in the time value of money registers
+func (r *RpnCalc) Amortize(from, to int) ([]Amortization, error) {
+	t := r.tvm
+	if from < 1 || to < from || float64(to) > math.Ceil(t.N) {
+		return nil, errValueNotAllowed
+	}
+
+	i := t.I / 100
+	rows := []Amortization{}
+	b := t.PV
+	for k := 1; k <= to; k++ {
+		owed := b
+		if t.Begin {
+			owed += t.PMT
+		}
+		interest := -owed * i
+		principal := t.PMT - interest
+		b += principal
+
+		if k >= from {
+			rows = append(rows, Amortization{k, interest, principal, b})
+		}
+	}
+	return rows, nil
+}
+
+// tvmStore stores x in a time value of money register
+func (r *RpnCalc) tvmStore(reg *float64) error {
+	if err := r.realArgs(1); err != nil {
+		return err
+	}
+	*reg = r.Val().Float64()
+	return nil
+}
+
+// tvmSolve solves and stores the register with the name and pushes it
+func (r *RpnCalc) tvmSolve(name string) error {
+	z, err := r.tvm.solve(name)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(z) || math.IsInf(z, 0) {
+		return errNoSolution
+	}
+
+	if err := r.pushFloat(z); err != nil {
+		return err
+	}
+	*r.tvmReg(name) = z
+	return nil
+}
+
+// popCount removes x and returns it as a positive count of the values
+// below it
+func (r *RpnCalc) popCount() (int, error) {
+	x := r.Val().Float64()
+	if x < 1 || x != math.Trunc(x) || x > float64(maxCashFlows) {
+		return 0, errValueNotAllowed
+	}
+
+	n := int(x)
+	if err := r.need(n + 1); err != nil {
+		return 0, err
+	}
+	if err := r.realArgs(n + 1); err != nil {
+		return 0, err
+	}
+
+	r.pop()
+	return n, nil
+}
+
+// npv returns the net present value of the cash flows at the rate i
+func npv(cfs []float64, i float64) float64 {
+	sum := 0.0
+	for k, cf := range cfs {
+		sum += cf / math.Pow(1+i, float64(k))
+	}
+	return sum
+}
+
+func opTVMN(r *RpnCalc, _ string) error {
+	return r.tvmSolve("n")
+}
+
+func opTVMI(r *RpnCalc, _ string) error {
+	return r.tvmSolve("i")
+}
+
+func opTVMPV(r *RpnCalc, _ string) error {
+	return r.tvmSolve("pv")
+}
+
+func opTVMPMT(r *RpnCalc, _ string) error {
+	return r.tvmSolve("pmt")
+}
+
+func opTVMFV(r *RpnCalc, _ string) error {
+	return r.tvmSolve("fv")
+}
+
+func opTVMClear(r *RpnCalc, _ string) error {
+	r.ClearTVM()
+	return nil
+}
+
+func opPaymentBegin(r *RpnCalc, _ string) error {
+	r.tvm.Begin = true
+	return nil
+}
+
+func opPaymentEnd(r *RpnCalc, _ string) error {
+	r.tvm.Begin = false
+	return nil
+}
+
+// opAmortize replaces the first and last period, y and x, with the total
+// interest, the total principal and the remaining balance
+func opAmortize(r *RpnCalc, _ string) error {
+	if err := r.realArgs(2); err != nil {
+		return err
+	}
+
+	from, to := r.stack[1].Float64(), r.stack[0].Float64()
+	if from != math.Trunc(from) || to != math.Trunc(to) {
+		return errNotInteger
+	}
+	if from < 1 || to < from || to > math.Ceil(r.tvm.N) {
+		return errValueNotAllowed
+	}
+
+	rows, err := r.Amortize(int(from), int(to))
+	if err != nil {
+		return err
+	}
+
+	interest, principal := 0.0, 0.0
+	for _, a := range rows {
+		interest += a.Interest
+		principal += a.Principal
+	}
+
+	r.pop()
+	r.pop()
+	if err := r.pushPair(principal, interest); err != nil {
+		return err
+	}
+	return r.pushFloat(rows[len(rows)-1].Balance)
+}
+
+// opNPV replaces the rate x, the count y and the y cash flows below them
+// with the net present value, the deepest cash flow being at period 0
+func opNPV(r *RpnCalc, _ string) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+
+	i := r.Val().Float64() / 100
+	if i <= -1 {
+		return errDomain
+	}
+	r.pop()
+
+	n, err := r.popCount()
+	if err != nil {
+		return err
+	}
+
+	v := npv(r.popFlows(n), i)
+	return r.pushFloat(v)
+}
+
+// opIRR replaces the count x and the x cash flows below it with the
+// internal rate of return in percent
+func opIRR(r *RpnCalc, _ string) error {
+	n, err := r.popCount()
+	if err != nil {
+		return err
+	}
+
+	cfs := r.popFlows(n)
+	z, err := solveRate(func(i float64) float64 {
+		return npv(cfs, i)
+	})
+	if err != nil {
+		return err
+	}
+	return r.pushFloat(z * 100)
+}
+
+// regCount removes x and returns it as a count of registers, from
+// register 0, holding cash flows
+func (r *RpnCalc) regCount() (int, error) {
+	if err := r.realArgs(1); err != nil {
+		return 0, err
+	}
+	x := r.Val().Float64()
+	if x < 1 || x != math.Trunc(x) || x > float64(len(r.regs)) {
+		return 0, errValueNotAllowed
+	}
+	for _, v := range r.regs[:int(x)] {
+		if v.IsComplex() || v.IsTime() || v.Unit() != nil {
+			return 0, errInvalidRegister
+		}
+	}
+
+	r.pop()
+	return int(x), nil
+}
+
+// regFlows returns the cash flows in the first n registers
+func (r *RpnCalc) regFlows(n int) []float64 {
+	cfs := make([]float64, n)
+	for k := range cfs {
+		cfs[k] = r.regs[k].Float64()
+	}
+	return cfs
+}
+
+// opRegNPV replaces the rate x and the count y with the net present value
+// of the cash flows in the registers from 0, register 0 being period 0
+func opRegNPV(r *RpnCalc, _ string) error {
+	if err := r.realArgs(1); err != nil {
+		return err
+	}
+	i := r.Val().Float64() / 100
+	if i <= -1 {
+		return errDomain
+	}
+	r.pop()
+
+	n, err := r.regCount()
+	if err != nil {
+		return err
+	}
+	return r.pushFloat(npv(r.regFlows(n), i))
+}
+
+// opRegIRR replaces the count x with the internal rate of return in
+// percent of the cash flows in the registers from 0
+func opRegIRR(r *RpnCalc, _ string) error {
+	n, err := r.regCount()
+	if err != nil {
+		return err
+	}
+
+	cfs := r.regFlows(n)
+	z, err := solveRate(func(i float64) float64 {
+		return npv(cfs, i)
+	})
+	if err != nil {
+		return err
+	}
+	return r.pushFloat(z * 100)
+}
+
+// popFlows removes n values from the stack and returns them, the deepest
+// value first
+func (r *RpnCalc) popFlows(n int) []float64 {
+	cfs := make([]float64, n)
+	for k := n - 1; k >= 0; k-- {
+		cfs[k] = r.pop().Float64()
+	}
+	return cfs
+}
+
+// opEffectiveRate converts the nominal annual rate y in percent with x
+// compounding periods per year to the effective annual rate
+func opEffectiveRate(r *RpnCalc, _ string) error {
+	return r.binaryOp(func(y, x float64) (float64, error) {
+		if x < 1 || x != math.Trunc(x) {
+			return 0, errValueNotAllowed
+		}
+		return (math.Pow(1+y/100/x, x) - 1) * 100, nil
+	})
+}
+
+// opNominalRate converts the effective annual rate y in percent to the
+// nominal annual rate with x compounding periods per year
+func opNominalRate(r *RpnCalc, _ string) error {
+	return r.binaryOp(func(y, x float64) (float64, error) {
+		if x < 1 || x != math.Trunc(x) {
+			return 0, errValueNotAllowed
+		}
+		if y <= -100 {
+			return 0, errDomain
+		}
+		return x * (math.Pow(1+y/100, 1/x) - 1) * 100, nil
+	})
+}
@@ -0,0 +1,135 @@
+package rpncalc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTVM(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		err   error
+	}{
+		{"payment", "360 rsn 0.5 rsi 200000 rspv 0 rsfv pmt", -1199.101050, nil},
+		{"payment without interest", "10 rsn 0 rsi 1000 rspv 0 rsfv pmt", -100, nil},
+		{"payment in begin mode", "pbeg 12 rsn 1 rsi 1000 rspv 0 rsfv pmt", -87.969097, nil},
+		{"future value", "10 rsn 5 rsi -1000 rspv 0 rspmt fv", 1628.894627, nil},
+		{"present value", "10 rsn 5 rsi 0 rspmt 1628.894627 rsfv pv", -1000, nil},
+		{"number of periods", "5 rsi -1000 rspv 0 rspmt 1628.894627 rsfv nper", 10, nil},
+		{"periods without interest", "0 rsi 1000 rspv -100 rspmt 0 rsfv nper", 10, nil},
+		{"interest rate", "10 rsn -1000 rspv 0 rspmt 1628.894627 rsfv irate", 5, nil},
+		{"interest rate of annuity", "360 rsn 200000 rspv -1199.101050 rspmt 0 rsfv irate", 0.5, nil},
+		{"interest rate without registers", "3 4 irate", 0, errNoSolution},
+		{"interest rate without cash flows", "10 rsn irate", 0, errNoSolution},
+		{"interest rate of zero", "10 rsn -1000 rspv 0 rspmt 1000 rsfv irate", 0, nil},
+		{"solve is stored", "10 rsn 5 rsi -1000 rspv 0 rspmt fv drop rrfv", 1628.894627, nil},
+		{"recall register", "3 rspmt rrpmt", 3, nil},
+		{"clear register", "3 rspmt rcpmt rrpmt", 0, nil},
+		{"clear all", "3 rspmt tvmclear rrpmt", 0, nil},
+		{"payment below interest", "5 rsi 1000 rspv -10 rspmt 0 rsfv nper", 0, errNoSolution},
+		{"no interest rate", "10 rsn 1000 rspv 100 rspmt 0 rsfv irate", 0, errNoSolution},
+		{"payment without periods", "5 rsi 1000 rspv pmt", 0, errDivisionByZero},
+		{"store complex", "2i rsn", 0, errComplex},
+		{"store unit", "2 m rsn", 0, errUnits},
+		{"amortize interest", "12 rsn 1 rsi 1000 rspv 0 rsfv pmt 1 3 amort rot", -27.626651, nil},
+		{"amortize principal", "12 rsn 1 rsi 1000 rspv 0 rsfv pmt 1 3 amort drop", -238.919715, nil},
+		{"amortize balance", "12 rsn 1 rsi 1000 rspv 0 rsfv pmt 1 3 amort", 761.080285, nil},
+		{"amortize all", "12 rsn 1 rsi 1000 rspv 0 rsfv pmt 1 12 amort", 0, nil},
+		{"amortize beyond loan", "12 rsn 1 rsi 1000 rspv 0 rsfv pmt 1 13 amort", 0, errValueNotAllowed},
+		{"amortize backwards", "12 rsn 3 1 amort", 0, errValueNotAllowed},
+		{"effective rate", "12 12 eff", 12.682503, nil},
+		{"nominal rate", "12.682503 12 nom", 12, nil},
+		{"effective rate periods", "12 0 eff", 0, errValueNotAllowed},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if got := r.Val().Float64(); math.Abs(got-c.exp) > 1e-5 {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, got)
+		}
+	}
+}
+
+func TestCashFlows(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		err   error
+	}{
+		{"net present value", "-1000 300 400 500 4 10 npv", -21.036814, nil},
+		{"net present value without rate", "-1000 300 400 500 4 0 npv", 200, nil},
+		{"internal rate of return", "-1000 300 400 500 4 irr", 8.896339, nil},
+		{"irr zero npv", "-1000 300 400 500 4 irr 5 swap npv", 0, nil},
+		{"irr without solution", "1000 300 2 irr", 0, errNoSolution},
+		{"count not allowed", "1 2 0 irr", 0, errValueNotAllowed},
+		{"count not integer", "1 2 1.5 irr", 0, errValueNotAllowed},
+		{"cash flow with unit", "-1000 1100 m 2 irr", 0, errUnits},
+		{"too many cash flows", "1 2 1001 irr", 0, errValueNotAllowed},
+		{"register net present value", "-1000 rs0 300 rs1 400 rs2 500 rs3 clst 4 10 rnpv", -21.036814, nil},
+		{"register internal rate of return", "-1000 rs0 300 rs1 400 rs2 500 rs3 clst 4 rirr", 8.896339, nil},
+		{"register irr without solution", "1000 rs0 300 rs1 clst 2 rirr", 0, errNoSolution},
+		{"register count too large", "11 rirr", 0, errValueNotAllowed},
+		{"register count zero", "0 rirr", 0, errValueNotAllowed},
+		{"register with unit", "-1000 rs0 1100 m rs1 clst 2 rirr", 0, errInvalidRegister},
+	}
+
+	for _, c := range cases {
+		r := New()
+		if err := r.SetStackSize(0); err != nil {
+			t.Fatalf("Unexpected error %v", err)
+		}
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if got := r.Val().Float64(); math.Abs(got-c.exp) > 1e-5 {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, got)
+		}
+		if r.Depth() != 1 {
+			t.Errorf("%q: Expected a single value on the stack, but got %v", c.name, r.Depth())
+		}
+	}
+}
+
+func TestAmortize(t *testing.T) {
+	r := New()
+
+	if err := r.Evaluate("12 rsn 1 rsi 1000 rspv 0 rsfv pmt"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	rows, err := r.Amortize(2, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(rows) != 2 || rows[0].Period != 2 || rows[1].Period != 3 {
+		t.Fatalf("Expected periods 2 and 3, but got %v", rows)
+	}
+	if math.Abs(rows[0].Interest+9.211512) > 1e-5 || math.Abs(rows[1].Balance-761.080285) > 1e-5 {
+		t.Errorf("Unexpected amortization %v", rows)
+	}
+	if _, err := r.Amortize(0, 3); err != errValueNotAllowed {
+		t.Errorf("Expected error %v, but got %v", errValueNotAllowed, err)
+	}
+}
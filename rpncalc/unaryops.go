@@ -3,26 +3,116 @@ package rpncalc
 
 import (
 	"math"
-	"strconv"
-	"strings"
+	"math/big"
+	"math/cmplx"
 )
 
 func (r *RpnCalc) unaryOp(f func(float64, string) (float64, error)) error {
-	v, err := f(r.stack[0], "")
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noUnits(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[0].Float64(), "")
 	if err != nil {
 		return err
 	}
-	r.stack[0] = v
+
+	v, err := r.value(z)
+	if err != nil {
+		return err
+	}
+
+	r.setX(v)
+	return nil
+}
+
+func (r *RpnCalc) bigUnaryOp(f func(*big.Float) (*big.Float, error)) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noUnits(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	z, err := f(r.stack[0].Big(r.prec))
+	if err != nil {
+		return err
+	}
+	r.setX(NewBig(z))
 	return nil
 }
 
 func opNegate(r *RpnCalc, _ string) error {
+	if r.unitArgs(1) {
+		return r.keepUnitOp(opNegate, "")
+	}
+
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return -x, nil
+		})
+	}
+
+	if r.ratArgs(1) {
+		return r.ratUnaryOp(func(x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Neg(x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigUnaryOp(func(x *big.Float) (*big.Float, error) {
+			return r.newBig().Neg(x), nil
+		})
+	}
+
 	return r.unaryOp(func(x float64, _ string) (float64, error) {
 		return x * (-1.0), nil
 	})
 }
 
 func opInverse(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			if x == 0 {
+				return 0, errDivisionByZero
+			}
+			return 1 / x, nil
+		})
+	}
+
+	if r.ratArgs(1) {
+		return r.ratUnaryOp(func(x *big.Rat) (*big.Rat, error) {
+			if x.Sign() == 0 {
+				return nil, errDivisionByZero
+			}
+			return new(big.Rat).Inv(x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigUnaryOp(func(x *big.Float) (*big.Float, error) {
+			if x.Sign() == 0 {
+				return nil, errDivisionByZero
+			}
+			return r.newBig().Quo(big.NewFloat(1), x), nil
+		})
+	}
+
 	return r.unaryOp(func(x float64, _ string) (float64, error) {
 		if x == 0.0 {
 			return 0.0, errDivisionByZero
@@ -33,6 +123,24 @@ func opInverse(r *RpnCalc, _ string) error {
 }
 
 func opSquare(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, nil) {
+		return r.complexUnaryOp(func(x complex128) (complex128, error) {
+			return x * x, nil
+		})
+	}
+
+	if r.ratArgs(1) {
+		return r.ratUnaryOp(func(x *big.Rat) (*big.Rat, error) {
+			return new(big.Rat).Mul(x, x), nil
+		})
+	}
+
+	if r.precise() {
+		return r.bigUnaryOp(func(x *big.Float) (*big.Float, error) {
+			return r.newBig().Mul(x, x), nil
+		})
+	}
+
 	return r.unaryOp(func(x float64, _ string) (float64, error) {
 		if x > math.Sqrt(math.MaxFloat64) {
 			return 0.0, errOverflow
@@ -42,6 +150,19 @@ func opSquare(r *RpnCalc, _ string) error {
 }
 
 func opSquareRoot(r *RpnCalc, _ string) error {
+	if r.complexArgs(1, negative) {
+		return r.complexUnaryOp(complexFunc(cmplx.Sqrt))
+	}
+
+	if r.precise() {
+		return r.bigUnaryOp(func(x *big.Float) (*big.Float, error) {
+			if x.Sign() < 0 {
+				return nil, errNaN
+			}
+			return r.newBig().Sqrt(x), nil
+		})
+	}
+
 	return r.unaryOp(func(x float64, _ string) (float64, error) {
 		r := math.Sqrt(x)
 		if math.IsNaN(r) {
@@ -50,43 +171,3 @@ func opSquareRoot(r *RpnCalc, _ string) error {
 		return r, nil
 	})
 }
-
-func opDecToBin(r *RpnCalc, _ string) error {
-	return r.unaryOp(func(x float64, _ string) (float64, error) {
-		dec := int64(x)
-		bin := int64(0)
-		i := 0
-
-		for dec > 0 {
-			lastBin := bin
-			bin += int64(math.Pow10(i)) * (dec % int64(2))
-			dec = dec / 2
-			i++
-			if lastBin > bin {
-				return 0.0, errOverflow
-			}
-		}
-
-		return float64(bin), nil
-	})
-}
-
-func opBinToDec(r *RpnCalc, _ string) error {
-	return r.unaryOp(func(x float64, _ string) (float64, error) {
-		bin := int64(x)
-		dec := int64(0)
-		i := 0
-
-		if "" != strings.ReplaceAll(strings.ReplaceAll(strconv.FormatInt(bin, 10), "1", ""), "0", "") {
-			return 0.0, errNoBinaryNumber
-		}
-
-		for bin >= 1 {
-			dec += int64(math.Pow(2, float64(i))) * (bin % 10)
-			bin = bin / 10
-			i++
-		}
-
-		return float64(dec), nil
-	})
-}
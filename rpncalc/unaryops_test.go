@@ -27,7 +27,7 @@ func TestUnaryOp(t *testing.T) {
 		if err != c.err {
 			t.Errorf("Expected result %v, but got %v", c.err, err)
 		}
-		if r.Stack()[0] != c.val {
+		if r.Stack()[0].Float64() != c.val {
 			t.Errorf("Expected value %v, but got %v", c.val, r.Stack()[0])
 		}
 	}
@@ -52,25 +52,13 @@ func TestUnaryOpBasicOperations(t *testing.T) {
 		{"square overflow", opSquare, 1e+155, 1e+155, errOverflow},
 		{"square root of 9", opSquareRoot, 9, 3, nil},
 		{"square root of -9", opSquareRoot, -9, -9, errNaN},
-		{"dec 1 as bin", opDecToBin, 1, 1, nil},
-		{"dec 2 as bin", opDecToBin, 2, 10, nil},
-		{"dec 3 as bin", opDecToBin, 3, 11, nil},
-		{"dec 255 as bin", opDecToBin, 255, 11111111, nil},
-		{"dec 256 as bin", opDecToBin, 256, 100000000, nil},
-		{"dec 678901234 as bin", opDecToBin, 67890, 10000100100110010, nil},
-		{"dec to bin overflow", opDecToBin, 6789012345, 0, errOverflow},
-		{"bin 0 to dec", opBinToDec, 0, 0, nil},
-		{"bin 1 to dec", opBinToDec, 1, 1, nil},
-		{"bin 10 to dec", opBinToDec, 10, 2, nil},
-		{"bin 1001001 to dec", opBinToDec, 1001001, 73, nil},
-		{"bin to dec not a binary number", opBinToDec, 123, 0, errNoBinaryNumber},
 
 		// TODO: Add more cases for unary operators
 	}
 
 	for _, c := range cases {
 		r := New()
-		r.stack[0] = c.v
+		r.stack[0] = NewFloat(c.v)
 
 		err := c.f(r, "")
 
@@ -83,7 +71,7 @@ func TestUnaryOpBasicOperations(t *testing.T) {
 			continue
 		}
 
-		got := r.stack[0]
+		got := r.stack[0].Float64()
 
 		if got != c.exp {
 			t.Errorf("%q: Expected result %v, but got %v", c.name, c.exp, got)
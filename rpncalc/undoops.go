@@ -0,0 +1,106 @@
+// Package rpncalc undo and redo operations
+package rpncalc
+
+// snapshot holds a copy of the engine state
+type snapshot struct {
+	stack []Value
+	depth int
+	regs  []Value
+	vars  map[string]Value
+	lastX Value
+	lastY Value
+	prec  uint
+	angle AngleMode
+	stats Stats
+	tvm   TVM
+	base  Base
+	size  int // integer word size
+	sign  bool
+	frac  bool
+	words map[string][]string
+}
+
+func (r *RpnCalc) snapshot() snapshot {
+	s := snapshot{
+		stack: make([]Value, len(r.stack)),
+		regs:  make([]Value, len(r.regs)),
+		depth: r.depth,
+		vars:  copyVars(r.vars),
+		lastX: r.lastX,
+		lastY: r.lastY,
+		prec:  r.prec,
+		angle: r.angle,
+		stats: r.stats,
+		tvm:   r.tvm,
+		base:  r.base,
+		size:  r.wordSize,
+		sign:  r.signed,
+		frac:  r.fraction,
+		words: copyWords(r.words),
+	}
+	copy(s.stack, r.stack)
+	copy(s.regs, r.regs)
+
+	return s
+}
+
+func (r *RpnCalc) restore(s snapshot) {
+	r.stack, r.depth = r.resize(s.stack, s.depth)
+	r.regs = s.regs
+	r.vars = s.vars
+	r.lastX = s.lastX
+	r.lastY = s.lastY
+	r.prec = s.prec
+	r.angle = s.angle
+	r.stats = s.stats
+	r.tvm = s.tvm
+	r.base = s.base
+	r.wordSize = s.size
+	r.signed = s.sign
+	r.fraction = s.frac
+	r.words = s.words
+}
+
+// record adds a snapshot to the undo history and clears the redo history
+func (r *RpnCalc) record(s snapshot) {
+	if r.undoDepth < 1 {
+		return
+	}
+
+	r.undos = append(r.undos, s)
+	if len(r.undos) > r.undoDepth {
+		r.undos = r.undos[len(r.undos)-r.undoDepth:]
+	}
+	r.redos = nil
+}
+
+func opUndo(r *RpnCalc, _ string) error {
+	if len(r.undos) < 1 {
+		return errNothingToUndo
+	}
+
+	s := r.undos[len(r.undos)-1]
+	r.undos = r.undos[:len(r.undos)-1]
+	r.redos = append(r.redos, r.snapshot())
+	r.restore(s)
+
+	return nil
+}
+
+func opRedo(r *RpnCalc, _ string) error {
+	if len(r.redos) < 1 {
+		return errNothingToRedo
+	}
+
+	s := r.redos[len(r.redos)-1]
+	r.redos = r.redos[:len(r.redos)-1]
+	r.undos = append(r.undos, r.snapshot())
+	r.restore(s)
+
+	return nil
+}
+
+// isUndoOp returns true for tokens that walk the undo history
+func isUndoOp(t string) bool {
+	return in(t, "undo", "redo")
+}
@@ -0,0 +1,85 @@
+package rpncalc
+
+import "testing"
+
+func TestUndoRedo(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		tokens bool     // undo per token
+		depth  int      // undo depth
+		input  []string // lines to evaluate
+		stack  []float64
+		err    error // expected error from last line
+	}{
+		{"undo line", false, 10,
+			[]string{"1 2", "3 4 +", "undo"}, []float64{2, 1, 0, 0}, nil},
+		{"undo two lines", false, 10,
+			[]string{"1 2", "3 4 +", "undo undo"}, []float64{0, 0, 0, 0}, nil},
+		{"undo and redo line", false, 10,
+			[]string{"1 2", "3 4 +", "undo", "redo"}, []float64{7, 2, 1, 1}, nil},
+		{"undo token", true, 10,
+			[]string{"1 2 3 4 +", "undo"}, []float64{4, 3, 2, 1}, nil},
+		{"undo tokens", true, 10,
+			[]string{"1 2 3 4 +", "undo undo undo"}, []float64{2, 1, 0, 0}, nil},
+		{"undo registers", false, 10,
+			[]string{"1 rs1", "2 rs1", "undo", "rr1"}, []float64{1, 1, 0, 0}, nil},
+		{"undo precision mode", false, 10,
+			[]string{"1", "big64", "undo"}, []float64{1, 0, 0, 0}, nil},
+		{"nothing to undo", false, 10,
+			[]string{"undo"}, []float64{0, 0, 0, 0}, errNothingToUndo},
+		{"nothing to redo", false, 10,
+			[]string{"1", "redo"}, []float64{1, 0, 0, 0}, errNothingToRedo},
+		{"new input clears redo", false, 10,
+			[]string{"1", "2", "undo", "3", "redo"}, []float64{3, 1, 0, 0}, errNothingToRedo},
+		{"undo depth limit", false, 1,
+			[]string{"1", "2", "undo", "undo"}, []float64{1, 0, 0, 0}, errNothingToUndo},
+		{"failed line is not recorded", false, 10,
+			[]string{"1", "2 foo", "undo"}, []float64{0, 0, 0, 0}, nil},
+		{"failed line restores undo history", true, 10,
+			[]string{"1", "2 undo 3 foo", "undo"}, []float64{0, 0, 0, 0}, nil},
+		{"undo disabled", false, 0,
+			[]string{"1", "undo"}, []float64{1, 0, 0, 0}, errNothingToUndo},
+	}
+
+	for _, c := range cases {
+		r := New()
+		r.SetUndoDepth(c.depth)
+		r.SetUndoTokens(c.tokens)
+
+		var err error
+		for _, line := range c.input {
+			err = r.Evaluate(line)
+		}
+
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		for i := range c.stack {
+			if c.stack[i] != r.stack[i].Float64() {
+				t.Errorf("%q: Expected stack %v, but got %v", c.name, c.stack, r.stack)
+				break
+			}
+		}
+	}
+}
+
+func TestUndoPrecisionIsRestored(t *testing.T) {
+	r := New()
+
+	err := r.Evaluate("big128")
+	if err != nil {
+		t.Fatalf("Could not switch to precision mode, got error %v", err)
+	}
+
+	err = r.Evaluate("undo")
+	if err != nil {
+		t.Fatalf("Could not undo, got error %v", err)
+	}
+
+	if r.Settings().Precision != 0 || r.Val().IsBig() {
+		t.Errorf("Expected float64 mode after undo, but got precision %v", r.Settings().Precision)
+	}
+}
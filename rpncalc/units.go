@@ -0,0 +1,415 @@
+// Package rpncalc units and unit conversion
+package rpncalc
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Dimension holds the exponents of the base quantities length, mass,
+// time, temperature and data
+type Dimension [5]int
+
+// Unit defines a unit as a factor, and for temperatures an offset, in the
+// base units of its dimension, m, kg, s, K and B
+type Unit struct {
+	Names       []string
+	Dim         Dimension
+	Factor      *big.Rat
+	Offset      *big.Rat // nil unless the unit has another zero point
+	Description string
+}
+
+// Name returns the short name of the unit
+func (u *Unit) Name() string {
+	return u.Names[0]
+}
+
+var (
+	length      = Dimension{1, 0, 0, 0, 0}
+	mass        = Dimension{0, 1, 0, 0, 0}
+	duration    = Dimension{0, 0, 1, 0, 0}
+	temperature = Dimension{0, 0, 0, 1, 0}
+	data        = Dimension{0, 0, 0, 0, 1}
+	speed       = Dimension{1, 0, -1, 0, 0}
+)
+
+// unit creates a unit from exact decimal or fraction strings
+func unit(names []string, dim Dimension, factor, offset, description string) *Unit {
+	u := &Unit{Names: names, Dim: dim, Description: description}
+	u.Factor, _ = new(big.Rat).SetString(factor)
+	if offset != "" {
+		u.Offset, _ = new(big.Rat).SetString(offset)
+	}
+	return u
+}
+
+var units = []*Unit{
+	// Length
+	unit([]string{"m", "meter"}, length, "1", "", "meter"),
+	unit([]string{"km"}, length, "1000", "", "kilometer"),
+	unit([]string{"cm"}, length, "0.01", "", "centimeter"),
+	unit([]string{"mm"}, length, "0.001", "", "millimeter"),
+	unit([]string{"um"}, length, "0.000001", "", "micrometer"),
+	unit([]string{"in", "inch"}, length, "0.0254", "", "inch"),
+	unit([]string{"ft", "foot"}, length, "0.3048", "", "foot"),
+	unit([]string{"yd", "yard"}, length, "0.9144", "", "yard"),
+	unit([]string{"mi", "mile"}, length, "1609.344", "", "mile"),
+	unit([]string{"nmi"}, length, "1852", "", "nautical mile"),
+	// Mass
+	unit([]string{"kg"}, mass, "1", "", "kilogram"),
+	unit([]string{"g", "gram"}, mass, "0.001", "", "gram"),
+	unit([]string{"mg"}, mass, "0.000001", "", "milligram"),
+	unit([]string{"t", "tonne"}, mass, "1000", "", "metric ton"),
+	unit([]string{"lb", "pound"}, mass, "0.45359237", "", "pound"),
+	unit([]string{"oz", "ounce"}, mass, "0.028349523125", "", "ounce"),
+	// Time
+	unit([]string{"s", "sec"}, duration, "1", "", "second"),
+	unit([]string{"ms"}, duration, "0.001", "", "millisecond"),
+	unit([]string{"us"}, duration, "0.000001", "", "microsecond"),
+	unit([]string{"minute"}, duration, "60", "", "minute"),
+	unit([]string{"hr", "hour"}, duration, "3600", "", "hour"),
+	unit([]string{"day"}, duration, "86400", "", "day"),
+	unit([]string{"week"}, duration, "604800", "", "week"),
+	unit([]string{"year"}, duration, "31557600", "", "julian year"),
+	// Temperature
+	unit([]string{"K", "kelvin"}, temperature, "1", "", "kelvin"),
+	unit([]string{"C", "degC"}, temperature, "1", "273.15", "degree Celsius"),
+	unit([]string{"F", "degF"}, temperature, "5/9", "45967/180", "degree Fahrenheit"),
+	// Data
+	unit([]string{"B", "byte"}, data, "1", "", "byte"),
+	unit([]string{"bit"}, data, "1/8", "", "bit"),
+	unit([]string{"kB"}, data, "1000", "", "kilobyte"),
+	unit([]string{"MB"}, data, "1000000", "", "megabyte"),
+	unit([]string{"GB"}, data, "1000000000", "", "gigabyte"),
+	unit([]string{"TB"}, data, "1000000000000", "", "terabyte"),
+	unit([]string{"KiB"}, data, "1024", "", "kibibyte"),
+	unit([]string{"MiB"}, data, "1048576", "", "mebibyte"),
+	unit([]string{"GiB"}, data, "1073741824", "", "gibibyte"),
+	unit([]string{"TiB"}, data, "1099511627776", "", "tebibyte"),
+	// Speed
+	unit([]string{"m/s"}, speed, "1", "", "meter per second"),
+	unit([]string{"km/h", "kph"}, speed, "5/18", "", "kilometer per hour"),
+	unit([]string{"mph"}, speed, "0.44704", "", "mile per hour"),
+	unit([]string{"kn", "knot"}, speed, "1852/3600", "", "knot"),
+}
+
+// Units returns the built-in units
+func Units() []*Unit {
+	return units
+}
+
+// findUnit returns the unit with the name, or nil
+func findUnit(name string) *Unit {
+	for _, u := range units {
+		if in(name, u.Names...) {
+			return u
+		}
+	}
+	return nil
+}
+
+// isUnit returns true if name is the name of a unit
+func isUnit(name string) bool {
+	return findUnit(name) != nil
+}
+
+// compose returns the unit of a product, or with sign -1 a quotient, of
+// values with the units a and b, nil meaning no unit, and the factor to
+// scale the product or quotient of the values with. Known units are looked
+// up by dimension and factor, other results get base units like m*m.
+func compose(a, b *Unit, sign int) (*Unit, *big.Rat, error) {
+	one := big.NewRat(1, 1)
+	switch {
+	case b == nil:
+		return a, one, nil
+	case a == nil && sign > 0:
+		return b, one, nil
+	case (a != nil && a.Offset != nil) || b.Offset != nil:
+		return nil, nil, errDimensionMismatch
+	}
+
+	var dim Dimension
+	factor := new(big.Rat).Set(b.Factor)
+	for i, d := range b.Dim {
+		dim[i] = sign * d
+	}
+	if sign < 0 {
+		factor.Inv(factor)
+	}
+	if a != nil {
+		for i, d := range a.Dim {
+			dim[i] += d
+		}
+		factor.Mul(factor, a.Factor)
+	}
+
+	for _, u := range units {
+		if u.Dim == dim && u.Offset == nil && u.Factor.Cmp(factor) == 0 {
+			return u, one, nil
+		}
+	}
+	return baseUnit(dim), factor, nil
+}
+
+// baseUnit returns the unit of a dimension in base units, or nil for no
+// dimension
+func baseUnit(dim Dimension) *Unit {
+	if dim == (Dimension{}) {
+		return nil
+	}
+
+	one := big.NewRat(1, 1)
+	for _, u := range units {
+		if u.Dim == dim && u.Offset == nil && u.Factor.Cmp(one) == 0 {
+			return u
+		}
+	}
+
+	name := baseName(dim)
+	return &Unit{Names: []string{name}, Dim: dim, Factor: one, Description: name}
+}
+
+// convert converts v from the unit from to the unit to, keeping the kind
+// of the value
+func convert(v Value, from, to *Unit) (Value, error) {
+	if from == to {
+		return v, nil
+	}
+	if from == nil || to == nil || from.Dim != to.Dim {
+		return Value{}, errDimensionMismatch
+	}
+
+	q := v.Rat()
+	if q == nil {
+		return Value{}, errNaN
+	}
+
+	q.Mul(q, from.Factor)
+	if from.Offset != nil {
+		q.Add(q, from.Offset)
+	}
+	if to.Offset != nil {
+		q.Sub(q, to.Offset)
+	}
+	q.Quo(q, to.Factor)
+
+	return scaled(v, q), nil
+}
+
+// scale multiplies v with the factor q, keeping the kind of the value
+func scale(v Value, q *big.Rat) (Value, error) {
+	if q.Cmp(big.NewRat(1, 1)) == 0 {
+		return v, nil
+	}
+
+	x := v.Rat()
+	if x == nil {
+		return Value{}, errNaN
+	}
+	return scaled(v, x.Mul(x, q)), nil
+}
+
+// scaled returns the exact result q as a value of the same kind as v
+func scaled(v Value, q *big.Rat) Value {
+	switch {
+	case v.IsRat():
+		return NewRat(q).withUnit(v.u)
+	case v.IsBig():
+		return NewBig(new(big.Float).SetPrec(v.b.Prec()).SetRat(q)).withUnit(v.u)
+	}
+	f, _ := q.Float64()
+	return NewFloat(f).withUnit(v.u)
+}
+
+// unitArgs returns true if any of the first n values on the stack has a
+// unit
+func (r *RpnCalc) unitArgs(n int) bool {
+	for i := 0; i < n && i < len(r.stack); i++ {
+		if r.stack[i].u != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// noUnits returns errUnits if any of the first n values has a unit
+func (r *RpnCalc) noUnits(n int) error {
+	if r.unitArgs(n) {
+		return errUnits
+	}
+	return nil
+}
+
+// sameUnitOp converts x to the unit of y and applies the operator f to the
+// values without units. With keep the result gets the unit of y.
+func (r *RpnCalc) sameUnitOp(f func(*RpnCalc, string) error, t string, keep bool) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+
+	u := r.stack[1].u
+	x, err := convert(r.stack[0], r.stack[0].u, u)
+	if err != nil {
+		return err
+	}
+	r.stack[0] = x.withUnit(nil)
+	r.stack[1] = r.stack[1].withUnit(nil)
+
+	if err := f(r, t); err != nil {
+		return err
+	}
+	if keep {
+		r.stack[0] = r.stack[0].withUnit(u)
+	}
+	return nil
+}
+
+// composeUnitOp applies the operator f to the values without units and
+// gives the result the product, or with sign -1 the quotient, of the units
+func (r *RpnCalc) composeUnitOp(f func(*RpnCalc, string) error, t string, sign int) error {
+	if err := r.need(2); err != nil {
+		return err
+	}
+	if err := r.notComplex(2); err != nil {
+		return err
+	}
+
+	u, q, err := compose(r.stack[1].u, r.stack[0].u, sign)
+	if err != nil {
+		return err
+	}
+	r.stack[0] = r.stack[0].withUnit(nil)
+	r.stack[1] = r.stack[1].withUnit(nil)
+
+	if err := f(r, t); err != nil {
+		return err
+	}
+
+	v, err := scale(r.stack[0], q)
+	if err != nil {
+		return err
+	}
+	r.stack[0] = v.withUnit(u)
+	return nil
+}
+
+// keepUnitOp applies the unary operator f to x without its unit and gives
+// the result the same unit
+func (r *RpnCalc) keepUnitOp(f func(*RpnCalc, string) error, t string) error {
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+
+	u := r.stack[0].u
+	r.stack[0] = r.stack[0].withUnit(nil)
+	if err := f(r, t); err != nil {
+		return err
+	}
+	r.stack[0] = r.stack[0].withUnit(u)
+	return nil
+}
+
+// applyUnit multiplies x with one of the unit u, i.e. 12 m gives x the
+// unit m and 3 m s gives 3 m*s
+func (r *RpnCalc) applyUnit(u *Unit) error {
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	x := r.stack[0]
+	if x.u == nil {
+		r.setX(x.withUnit(u))
+		return nil
+	}
+
+	c, q, err := compose(x.u, u, 1)
+	if err != nil {
+		return err
+	}
+	v, err := scale(x, q)
+	if err != nil {
+		return err
+	}
+	r.setX(v.withUnit(c))
+	return nil
+}
+
+// convertTo converts x to the unit with the name
+func (r *RpnCalc) convertTo(name string) error {
+	u := findUnit(name)
+	if u == nil {
+		return errUnknownUnit
+	}
+	if err := r.need(1); err != nil {
+		return err
+	}
+	if err := r.notComplex(1); err != nil {
+		return err
+	}
+	if err := r.noTimes(1); err != nil {
+		return err
+	}
+
+	x := r.stack[0]
+	v, err := convert(x, x.u, u)
+	if err != nil {
+		return err
+	}
+	r.setX(v.withUnit(u))
+	return nil
+}
+
+// opStripUnit removes the unit of x, keeping its value
+func opStripUnit(r *RpnCalc, _ string) error {
+	r.setX(r.stack[0].withUnit(nil))
+	return nil
+}
+
+// opBaseUnit converts x to the base units of its dimension
+func opBaseUnit(r *RpnCalc, _ string) error {
+	x := r.stack[0]
+	if x.u == nil {
+		return nil
+	}
+
+	u := baseUnit(x.u.Dim)
+	v, err := convert(x, x.u, u)
+	if err != nil {
+		return err
+	}
+	r.setX(v.withUnit(u))
+	return nil
+}
+
+// baseName returns a name like m*kg/s*s for a dimension in base units
+func baseName(d Dimension) string {
+	names := []string{"m", "kg", "s", "K", "B"}
+	var num, den []string
+	for i, e := range d {
+		for ; e > 0; e-- {
+			num = append(num, names[i])
+		}
+		for ; e < 0; e++ {
+			den = append(den, names[i])
+		}
+	}
+
+	n := strings.Join(num, "*")
+	if n == "" {
+		n = "1"
+	}
+	if len(den) > 0 {
+		n += "/" + strings.Join(den, "/")
+	}
+	return n
+}
@@ -0,0 +1,95 @@
+package rpncalc
+
+import "testing"
+
+func TestUnits(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   float64
+		unit  string
+		err   error
+	}{
+		{"assign unit", "12 m", 12, "m", nil},
+		{"add converts to unit of y", "1 m 50 cm +", 1.5, "m", nil},
+		{"subtract", "1 ft 6 in -", 0.5, "ft", nil},
+		{"add mismatch", "1 m 1 s +", 0, "", errDimensionMismatch},
+		{"add unitless", "1 m 1 +", 0, "", errDimensionMismatch},
+		{"multiply by scalar", "3 kg 2 *", 6, "kg", nil},
+		{"scalar times unit", "2 3 kg *", 6, "kg", nil},
+		{"divide by scalar", "3 kg 2 /", 1.5, "kg", nil},
+		{"known quotient", "100 m 10 s /", 10, "m/s", nil},
+		{"known quotient with factor", "60 mi 1 hr /", 60, "mph", nil},
+		{"product in base units", "3 m 2 m *", 6, "m*m", nil},
+		{"product scaled to base units", "3 km 2 m *", 6000, "m*m", nil},
+		{"quotient without dimension", "6 km 3 m /", 2000, "", nil},
+		{"inverse unit", "5 2 s /", 2.5, "1/s", nil},
+		{"unit after unit", "3 m s", 3, "m*s", nil},
+		{"negate keeps unit", "3 m neg", -3, "m", nil},
+		{"abs keeps unit", "-3 m abs", 3, "m", nil},
+		{"compare converts", "3 ft 1 m <", 1, "", nil},
+		{"compare mismatch", "3 ft 1 s <", 0, "", errDimensionMismatch},
+		{"other operations fail", "9 m sqrt", 0, "", errUnits},
+		{"bitwise fails", "9 B 1 and", 0, "", errUnits},
+		{"convert length", "1 mi to km", 1.609344, "km", nil},
+		{"convert celsius", "100 C to F", 212, "F", nil},
+		{"convert fahrenheit", "32 F to C", 0, "C", nil},
+		{"convert kelvin", "0 C to K", 273.15, "K", nil},
+		{"convert data", "1 KiB to bit", 8192, "bit", nil},
+		{"convert speed", "10 m/s to km/h", 36, "km/h", nil},
+		{"convert time", "2 hr to minute", 120, "minute", nil},
+		{"convert mismatch", "1 m to kg", 0, "", errDimensionMismatch},
+		{"convert unitless", "1 to kg", 0, "", errDimensionMismatch},
+		{"convert unknown unit", "1 m to parsec", 0, "", errUnknownUnit},
+		{"convert missing unit", "1 m to", 0, "", errUnknownUnit},
+		{"temperature product fails", "20 C 2 C *", 0, "", errDimensionMismatch},
+		{"strip unit", "3 m nounit", 3, "", nil},
+		{"base units", "2 km si", 2000, "m", nil},
+		{"fraction keeps exact", "1/2 in 1/4 in +", 0.75, "in", nil},
+		{"precision mode", "big128 1 mi to km", 1.609344, "km", nil},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		err := r.Evaluate(c.input)
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		v := r.Val()
+		if !almostEqual(v.Float64(), c.exp) {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.exp, v)
+		}
+		unit := ""
+		if v.Unit() != nil {
+			unit = v.Unit().Name()
+		}
+		if unit != c.unit {
+			t.Errorf("%q: Expected unit %q, but got %q", c.name, c.unit, unit)
+		}
+	}
+}
+
+func TestUnitText(t *testing.T) {
+	r := New()
+
+	if err := r.Evaluate("1.5 m"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if s := r.Val().String(); s != "1.5 m" {
+		t.Errorf("Expected string %q, but got %q", "1.5 m", s)
+	}
+	if s := r.Format(r.Val(), 2); s != "1.50 m" {
+		t.Errorf("Expected format %q, but got %q", "1.50 m", s)
+	}
+	if err := r.Evaluate(": m 1 ;"); err != errInvalidName {
+		t.Errorf("Expected unit name to be an invalid word name, but got %v", err)
+	}
+}
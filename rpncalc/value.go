@@ -0,0 +1,221 @@
+// Package rpncalc values
+package rpncalc
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+// Value is a number on the stack or in a register. A value is either a
+// float64, in precision mode a big.Float, a complex128, an exact
+// fraction or a date. Values are never modified, operations always create new values.
+type Value struct {
+	f    float64
+	b    *big.Float // nil unless the value is a big value
+	c    complex128
+	cplx bool       // the value is the complex value c
+	q    *big.Rat   // nil unless the value is a fraction
+	u    *Unit      // nil unless the value has a unit
+	t    *time.Time // nil unless the value is a date
+}
+
+// NewFloat creates a float64 value
+func NewFloat(f float64) Value {
+	return Value{f: f}
+}
+
+// NewBig creates an arbitrary precision value
+func NewBig(b *big.Float) Value {
+	return Value{b: b}
+}
+
+// NewComplex creates a complex value
+func NewComplex(c complex128) Value {
+	return Value{c: c, cplx: true}
+}
+
+// NewRat creates an exact fraction value
+func NewRat(q *big.Rat) Value {
+	return Value{q: q}
+}
+
+// NewTime creates a date value
+func NewTime(t time.Time) Value {
+	return Value{t: &t}
+}
+
+// IsTime returns true if the value is a date
+func (v Value) IsTime() bool {
+	return v.t != nil
+}
+
+// Time returns the date of the value, the zero time for other values
+func (v Value) Time() time.Time {
+	if v.t == nil {
+		return time.Time{}
+	}
+	return *v.t
+}
+
+// IsRat returns true if the value is an exact fraction
+func (v Value) IsRat() bool {
+	return v.q != nil
+}
+
+// Rat returns the value as an exact fraction, or nil for values that are
+// not finite real numbers
+func (v Value) Rat() *big.Rat {
+	switch {
+	case v.q != nil:
+		return new(big.Rat).Set(v.q)
+	case v.cplx, v.t != nil:
+		return nil
+	case v.b != nil:
+		if v.b.IsInf() {
+			return nil
+		}
+		q, _ := v.b.Rat(nil)
+		return q
+	case math.IsNaN(v.f) || math.IsInf(v.f, 0):
+		return nil
+	}
+	return new(big.Rat).SetFloat64(v.f)
+}
+
+// Unit returns the unit of the value, or nil for values without a unit
+func (v Value) Unit() *Unit {
+	return v.u
+}
+
+// withUnit returns a copy of the value with the unit u
+func (v Value) withUnit(u *Unit) Value {
+	v.u = u
+	return v
+}
+
+// unitSuffix returns the unit name to append to the value text
+func (v Value) unitSuffix() string {
+	if v.u == nil {
+		return ""
+	}
+	return " " + v.u.Name()
+}
+
+// IsComplex returns true if the value is a complex value
+func (v Value) IsComplex() bool {
+	return v.cplx
+}
+
+// Complex returns the value as a complex128
+func (v Value) Complex() complex128 {
+	if v.cplx {
+		return v.c
+	}
+	return complex(v.Float64(), 0)
+}
+
+// IsBig returns true if the value is an arbitrary precision value
+func (v Value) IsBig() bool {
+	return v.b != nil
+}
+
+// IsZero returns true if the value is zero
+func (v Value) IsZero() bool {
+	if v.t != nil {
+		return false
+	}
+	if v.cplx {
+		return v.c == 0
+	}
+	if v.q != nil {
+		return v.q.Sign() == 0
+	}
+	if v.b != nil {
+		return v.b.Sign() == 0
+	}
+	return v.f == 0
+}
+
+// Float64 returns the value as a float64, big values and fractions are
+// rounded, complex values return their real part and dates their Unix
+// time in seconds
+func (v Value) Float64() float64 {
+	if v.t != nil {
+		return float64(v.t.UnixNano()) / 1e9
+	}
+	if v.cplx {
+		return real(v.c)
+	}
+	if v.q != nil {
+		f, _ := v.q.Float64()
+		return f
+	}
+	if v.b != nil {
+		f, _ := v.b.Float64()
+		return f
+	}
+	return v.f
+}
+
+// Big returns the value as a big.Float with precision prec, complex values
+// return their real part
+func (v Value) Big(prec uint) *big.Float {
+	if v.b != nil {
+		return new(big.Float).SetPrec(prec).Set(v.b)
+	}
+	if v.q != nil {
+		return new(big.Float).SetPrec(prec).SetRat(v.q)
+	}
+	f := v.Float64()
+	if math.IsNaN(f) {
+		return new(big.Float).SetPrec(prec)
+	}
+	return new(big.Float).SetPrec(prec).SetFloat64(f)
+}
+
+// Text formats the value with prec decimals, followed by its unit
+func (v Value) Text(prec int) string {
+	return v.numberText(prec) + v.unitSuffix()
+}
+
+// numberText formats the value without unit with prec decimals
+func (v Value) numberText(prec int) string {
+	if v.t != nil {
+		return v.t.Format("2006-01-02 15:04:05 MST")
+	}
+	if v.cplx {
+		return fmt.Sprintf("%.*f%+.*fi", prec, real(v.c), prec, imag(v.c))
+	}
+	if v.q != nil {
+		return v.q.FloatString(prec)
+	}
+	if v.b != nil {
+		return v.b.Text('f', prec)
+	}
+	return fmt.Sprintf("%.*f", prec, v.f)
+}
+
+// String returns the shortest representation of the value and its unit
+func (v Value) String() string {
+	return v.numberString() + v.unitSuffix()
+}
+
+// numberString returns the shortest representation of the value without
+// unit
+func (v Value) numberString() string {
+	if v.t != nil {
+		return v.t.Format(time.RFC3339Nano)
+	}
+	if v.cplx {
+		return fmt.Sprintf("%v%+vi", real(v.c), imag(v.c))
+	}
+	if v.q != nil {
+		return v.q.RatString()
+	}
+	if v.b != nil {
+		return v.b.Text('g', -1)
+	}
+	return fmt.Sprintf("%v", v.f)
+}
@@ -0,0 +1,42 @@
+package rpncalc
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestValueConversions(t *testing.T) {
+
+	cases := []struct {
+		name string
+		v    Value
+		big  bool
+		f    float64
+		text string
+		str  string
+	}{
+		{"float", NewFloat(1.5), false, 1.5, "1.50", "1.5"},
+		{"negative float", NewFloat(-2), false, -2, "-2.00", "-2"},
+		{"big", NewBig(big.NewFloat(1.5)), true, 1.5, "1.50", "1.5"},
+		{"zero value", Value{}, false, 0, "0.00", "0"},
+		{"complex", NewComplex(3 - 4i), false, 3, "3.00-4.00i", "3-4i"},
+	}
+
+	for _, c := range cases {
+		if c.v.IsBig() != c.big {
+			t.Errorf("%q: Expected IsBig %v, but got %v", c.name, c.big, c.v.IsBig())
+		}
+		if c.v.Float64() != c.f {
+			t.Errorf("%q: Expected float %v, but got %v", c.name, c.f, c.v.Float64())
+		}
+		if c.v.Text(2) != c.text {
+			t.Errorf("%q: Expected text %q, but got %q", c.name, c.text, c.v.Text(2))
+		}
+		if c.v.String() != c.str {
+			t.Errorf("%q: Expected string %q, but got %q", c.name, c.str, c.v.String())
+		}
+		if f, _ := c.v.Big(64).Float64(); f != c.f {
+			t.Errorf("%q: Expected big %v, but got %v", c.name, c.f, f)
+		}
+	}
+}
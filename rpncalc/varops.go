@@ -0,0 +1,84 @@
+// Package rpncalc named variables
+package rpncalc
+
+import "sort"
+
+// Variable is a named value, stored with =name and recalled with $name or
+// just its name
+type Variable struct {
+	Name  string
+	Value Value
+}
+
+// Vars returns the variables sorted by name
+func (r *RpnCalc) Vars() []Variable {
+	vs := []Variable{}
+	for n, v := range r.vars {
+		vs = append(vs, Variable{n, v})
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i].Name < vs[j].Name })
+
+	return vs
+}
+
+// ClearVar removes a variable
+func (r *RpnCalc) ClearVar(name string) error {
+	if _, ok := r.vars[name]; !ok {
+		return errUnknownVariable
+	}
+	delete(r.vars, name)
+	return nil
+}
+
+// ClearVars removes all variables
+func (r *RpnCalc) ClearVars() {
+	r.vars = map[string]Value{}
+}
+
+// validVar checks that a name can be used for a variable, it must be a
+// valid word name and not the name of a word
+func (r *RpnCalc) validVar(name string) bool {
+	if _, ok := r.vars[name]; ok {
+		return true
+	}
+	if _, ok := r.words[name]; ok {
+		return false
+	}
+	return name != "" && r.validName(name)
+}
+
+// pushVar pushes the value of the variable name, if there is one
+func (r *RpnCalc) pushVar(name string) bool {
+	v, ok := r.vars[name]
+	if ok {
+		r.push(v)
+	}
+	return ok
+}
+
+// dynOpVarStore stores x in the variable named after =, like =rate
+func dynOpVarStore(r *RpnCalc, t string) error {
+	name := t[1:]
+	if !r.validVar(name) {
+		return errInvalidName
+	}
+
+	r.vars[name] = r.Val()
+	return nil
+}
+
+// dynOpVarRecall pushes the variable named after $, like $rate
+func dynOpVarRecall(r *RpnCalc, t string) error {
+	if !r.pushVar(t[1:]) {
+		return errUnknownVariable
+	}
+	return nil
+}
+
+func copyVars(vs map[string]Value) map[string]Value {
+	c := make(map[string]Value, len(vs))
+	for n, v := range vs {
+		c[n] = v
+	}
+	return c
+}
@@ -0,0 +1,91 @@
+package rpncalc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVariables(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input string
+		exp   string
+		err   error
+	}{
+		{"store keeps x", "5 =rate", "5", nil},
+		{"single letter names", "5 =n 3 =i n i +", "8", nil},
+		{"recall", "5 =rate drop $rate", "5", nil},
+		{"recall by name", "5 =rate drop rate", "5", nil},
+		{"use in expression", "0.5 =rate 200 rate *", "100", nil},
+		{"overwrite", "5 =rate 7 =rate rate", "7", nil},
+		{"units", "3 m =len len 2 *", "6 m", nil},
+		{"fraction", "1/3 =third third 3 *", "1", nil},
+		{"use in word", "2 =gain : twice gain * ; 21 twice", "42", nil},
+		{"forget", "5 =rate forget rate rate", "", errUnknownInput},
+		{"forget recall", "5 =rate forget rate $rate", "", errUnknownVariable},
+		{"unknown variable", "$rate", "", errUnknownVariable},
+		{"missing name", "5 =", "", errInvalidName},
+		{"constant name", "5 =pi", "", errInvalidName},
+		{"operator name", "5 =sin", "", errInvalidName},
+		{"dynamic operator name", "5 =rr1", "", errInvalidName},
+		{"keyword name", "5 =if", "", errInvalidName},
+		{"number name", "5 =12", "", errInvalidName},
+		{"fraction name", "5 =1/3", "", errInvalidName},
+		{"literal name", "5 =0xff", "", errInvalidName},
+		{"duration name", "5 =1h30m", "", errInvalidName},
+		{"complex name", "5 =2i", "", errInvalidName},
+		{"date name", "5 =2024-03-01", "", errInvalidName},
+		{"overflow name", "5 =0x1ffffffffffffffff", "", errInvalidName},
+		{"word name", ": twice 2 * ; 5 =twice", "", errInvalidName},
+		{"word with variable name", "5 =twice : twice 2 * ;", "", errInvalidName},
+		{"undo", "5 =rate\nundo rate", "", errUnknownInput},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		var err error
+		for _, l := range strings.Split(c.input, "\n") {
+			if err = r.Evaluate(l); err != nil {
+				break
+			}
+		}
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+		if c.err != nil {
+			continue
+		}
+
+		if s := r.Val().String(); s != c.exp {
+			t.Errorf("%q: Expected %v, but got %v", c.name, c.exp, s)
+		}
+	}
+}
+
+func TestVars(t *testing.T) {
+	r := New()
+
+	if err := r.Evaluate("2 =b2 1 =a1"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	vs := r.Vars()
+	if len(vs) != 2 || vs[0].Name != "a1" || vs[1].Name != "b2" || vs[1].Value.String() != "2" {
+		t.Fatalf("Expected variables a1 and b2, but got %v", vs)
+	}
+
+	if err := r.ClearVar("a1"); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+	if err := r.ClearVar("a1"); err != errUnknownVariable {
+		t.Errorf("Expected error %v, but got %v", errUnknownVariable, err)
+	}
+
+	r.ClearVars()
+	if len(r.Vars()) != 0 {
+		t.Errorf("Expected no variables, but got %v", r.Vars())
+	}
+}
@@ -0,0 +1,115 @@
+// Package rpncalc user defined words
+package rpncalc
+
+import (
+	"sort"
+	"strings"
+)
+
+// Word is a user defined operator, a named sequence of tokens
+type Word struct {
+	Name string
+	Body []string
+}
+
+// Definition returns the word as a definition that can be evaluated
+func (w Word) Definition() string {
+	return ": " + w.Name + " " + strings.Join(w.Body, " ") + " ;"
+}
+
+// Words returns the user defined words sorted by name
+func (r *RpnCalc) Words() []Word {
+	ws := []Word{}
+	for n, b := range r.words {
+		ws = append(ws, Word{n, b})
+	}
+	sort.Slice(ws, func(i, j int) bool { return ws[i].Name < ws[j].Name })
+
+	return ws
+}
+
+// forget removes a user defined word or a variable
+func (r *RpnCalc) forget(name string) error {
+	if _, ok := r.vars[name]; ok {
+		return r.ClearVar(name)
+	}
+	return r.Forget(name)
+}
+
+// Forget removes a user defined word
+func (r *RpnCalc) Forget(name string) error {
+	if _, ok := r.words[name]; !ok {
+		return errUnknownWord
+	}
+	delete(r.words, name)
+	return nil
+}
+
+// ClearWords removes all user defined words
+func (r *RpnCalc) ClearWords() {
+	r.words = map[string][]string{}
+}
+
+// define parses a definition, ": name tokens ;", at the start of ts and
+// returns the number of tokens used
+func (r *RpnCalc) define(ts []string) (int, error) {
+	end := -1
+	for i, t := range ts {
+		if t == ";" {
+			end = i
+			break
+		}
+	}
+	if end < 2 {
+		return 0, errInvalidDefinition
+	}
+
+	name, body := ts[1], ts[2:end]
+	if !r.validName(name) {
+		return 0, errInvalidName
+	}
+	for _, t := range body {
+		if t == ":" {
+			return 0, errInvalidDefinition
+		}
+	}
+
+	r.words[name] = append([]string{}, body...)
+	return end + 1, nil
+}
+
+// call evaluates the body of a user defined word
+func (r *RpnCalc) call(body []string) error {
+	if r.calls >= maxCallDepth {
+		return errCallDepth
+	}
+
+	r.calls++
+	defer func() { r.calls-- }()
+
+	return r.evaluate(body)
+}
+
+// validName checks that a name can be used for a word, i.e. that it can
+// not be mistaken for a number, a fraction, a literal, a date, a constant,
+// an operator, a keyword or a variable
+func (r *RpnCalc) validName(name string) bool {
+	if _, ok := r.vars[name]; ok || isKeyword(name) {
+		return false
+	}
+	if r.isNumber(name) {
+		return false
+	}
+	if isConstant(name) || isUnit(name) || lookupOp(name) != nil {
+		return false
+	}
+	return true
+}
+
+func copyWords(ws map[string][]string) map[string][]string {
+	c := make(map[string][]string, len(ws))
+	for n, b := range ws {
+		c[n] = b
+	}
+	return c
+}
@@ -0,0 +1,143 @@
+package rpncalc
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+
+	cases := []struct {
+		name  string
+		input []string
+		val   float64
+		err   error
+	}{
+		{"define and call",
+			[]string{": hyp sq sw sq + sqrt ;", "3 4 hyp"}, 5, nil},
+		{"define and call on one line",
+			[]string{": hyp sq sw sq + sqrt ; 3 4 hyp"}, 5, nil},
+		{"word calling word",
+			[]string{": double 2 * ;", ": quad double double ;", "3 quad"}, 12, nil},
+		{"word using constants and registers",
+			[]string{": circ sq pi * rs1 ;", "2 circ rr1"}, 12.566370614359172, nil},
+		{"redefine word",
+			[]string{": w 1 + ;", ": w 2 + ;", "1 w"}, 3, nil},
+		{"forget word",
+			[]string{": w 1 + ;", "forget w", "1 w"}, 1, errUnknownInput},
+		{"forget unknown word",
+			[]string{"forget w"}, 0, errUnknownWord},
+		{"forget without name",
+			[]string{"forget"}, 0, errUnknownWord},
+		{"missing end",
+			[]string{": w 1 +"}, 0, errInvalidDefinition},
+		{"missing name",
+			[]string{": ;"}, 0, errInvalidDefinition},
+		{"nested definition",
+			[]string{": w : v 1 ; ;"}, 0, errInvalidDefinition},
+		{"number as name",
+			[]string{": 12 1 + ;"}, 0, errInvalidName},
+		{"fraction as name",
+			[]string{": 1/3 99 ;"}, 0, errInvalidName},
+		{"literal as name",
+			[]string{": 0xff 99 ;"}, 0, errInvalidName},
+		{"constant as name",
+			[]string{": pi 1 + ;"}, 0, errInvalidName},
+		{"operator as name",
+			[]string{": sqrt 1 + ;"}, 0, errInvalidName},
+		{"dynamic operator as name",
+			[]string{": rs1 1 + ;"}, 0, errInvalidName},
+		{"error in word",
+			[]string{": w 0 / ;", "1 w"}, 0, errDivisionByZero},
+		{"unknown token in word",
+			[]string{": w foo ;", "1 w"}, 0, errUnknownInput},
+		{"endless recursion",
+			[]string{": w w ;", "1 w"}, 0, errCallDepth},
+	}
+
+	for _, c := range cases {
+		r := New()
+
+		var err error
+		for _, l := range c.input {
+			err = r.Evaluate(l)
+		}
+
+		if err != c.err {
+			t.Errorf("%q: Expected error %v, but got %v", c.name, c.err, err)
+			continue
+		}
+
+		if c.err == nil && !almostEqual(r.Val().Float64(), c.val) {
+			t.Errorf("%q: Expected value %v, but got %v", c.name, c.val, r.Val())
+		}
+	}
+}
+
+func TestWordsListing(t *testing.T) {
+
+	r := New()
+
+	for _, l := range []string{": twice 2 * ;", ": incr 1 + ;"} {
+		if err := r.Evaluate(l); err != nil {
+			t.Fatalf("Could not define word, got error %v", err)
+		}
+	}
+
+	exp := "[: incr 1 + ; : twice 2 * ;]"
+	defs := []string{}
+	for _, w := range r.Words() {
+		defs = append(defs, w.Definition())
+	}
+	if fmt.Sprintf("%v", defs) != exp {
+		t.Errorf("Expected definitions %v, but got %v", exp, defs)
+	}
+
+	// User words are listed after the operators
+	ois := r.OpsInfo()
+	last := ois[len(ois)-1]
+	if last.Type != WordOp || last.Names[0] != "twice" || last.Description == "" {
+		t.Errorf("Expected word twice last in operators info, but got %v", last)
+	}
+
+	if err := r.Forget("incr"); err != nil {
+		t.Errorf("Expected to forget word incr, but got error %v", err)
+	}
+	if len(r.Words()) != 1 {
+		t.Errorf("Expected one word, but got %v", r.Words())
+	}
+
+	r.ClearWords()
+	if len(r.Words()) != 0 {
+		t.Errorf("Expected no words, but got %v", r.Words())
+	}
+}
+
+func TestWordsUndoAndReplay(t *testing.T) {
+
+	r := New()
+
+	for _, l := range []string{": w 1 + ;", "forget w", "undo", "2 w"} {
+		if err := r.Evaluate(l); err != nil {
+			t.Fatalf("Failed to evaluate %q, got error %v", l, err)
+		}
+	}
+
+	if r.Val().Float64() != 3 {
+		t.Errorf("Expected value 3, but got %v", r.Val())
+	}
+
+	// Word bodies are not logged, only the calls
+	exp := ": w 1 + ;\nforget w\nundo\n2 w\n"
+	if Script(r.Log()) != exp {
+		t.Errorf("Expected script %q, but got %q", exp, Script(r.Log()))
+	}
+
+	replayed := New()
+	if err := replayed.Replay(r.Log()); err != nil {
+		t.Fatalf("Replay failed with error %v", err)
+	}
+	if replayed.Val().Float64() != 3 || len(replayed.Words()) != 1 {
+		t.Errorf("Expected value 3 and one word, but got %v and %v", replayed.Val(), replayed.Words())
+	}
+}
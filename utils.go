@@ -1,6 +1,8 @@
 // Package main utility funcs
 package main
 
+import "strings"
+
 func member(t string, ms ...string) bool {
 	for _, m := range ms {
 		if m == t {
@@ -21,3 +23,27 @@ func filter(ss []string, p func(s string) bool) []string {
 
 	return rs
 }
+
+// splitStatements splits input on separator tokens. A statement starting
+// with ":" is a word definition and is not split until its ending ";".
+func splitStatements(input, sep string) []string {
+	stmts := []string{}
+	stmt := []string{}
+	inDef := false
+
+	for _, t := range strings.Fields(input) {
+		switch {
+		case inDef:
+			inDef = t != ";"
+		case t == ":" && len(stmt) == 0:
+			inDef = true
+		case t == sep:
+			stmts = append(stmts, strings.Join(stmt, " "))
+			stmt = []string{}
+			continue
+		}
+		stmt = append(stmt, t)
+	}
+
+	return append(stmts, strings.Join(stmt, " "))
+}